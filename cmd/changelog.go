@@ -0,0 +1,117 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/signal"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/madflow/kommit/internal/changelog"
+	"github.com/madflow/kommit/internal/config"
+	"github.com/madflow/kommit/internal/git"
+	"github.com/madflow/kommit/internal/llm"
+	"github.com/madflow/kommit/internal/logger"
+	"github.com/spf13/cobra"
+)
+
+var (
+	changelogRelease string
+	changelogDate    string
+	changelogFile    string
+	changelogWrite   bool
+)
+
+// changelogCmd generates release notes from the commits of a range
+var changelogCmd = &cobra.Command{
+	Use:   "changelog <from>..<to>",
+	Short: "Generate release notes from the commits of a range",
+	Long: `Generate release notes in the Keep a Changelog format from the commits of a range.
+
+Conventional commits are sorted into the sections Added, Changed, Deprecated,
+Removed, Fixed and Security by their type (feat, fix, perf, ...); the other
+types of lint.types, like docs, test or chore, are left out. All other commits,
+e.g. "net: fix race" with a subsystem instead of a type, are sorted by the model.
+Merge commits are skipped.
+
+A single revision selects the commits after it up to HEAD, e.g. "kommit
+changelog v1.2.0". The release notes are printed to stdout, or inserted above
+the latest release of CHANGELOG.md with --write, or of another changelog file
+with --file <path>.`,
+	Args:        cobra.ExactArgs(1),
+	Annotations: map[string]string{stdoutAnnotation: ""},
+	Run: func(cmd *cobra.Command, args []string) {
+		if !git.IsGitRepo() {
+			logger.Fatal("Not in a git repository")
+		}
+
+		revRange := args[0]
+		if !strings.Contains(revRange, "..") {
+			revRange += "..HEAD"
+		}
+		commits, err := git.GetCommits("--no-merges", revRange)
+		if err != nil {
+			logger.Fatal("Error reading commits: %v", err)
+		}
+		if len(commits) == 0 {
+			logger.Fatal("No commits in %s", revRange)
+		}
+		// Changelogs list the latest changes first
+		slices.Reverse(commits)
+
+		cfg := config.Get()
+		provider, err := llm.New(cfg)
+		if err != nil {
+			logger.Fatal("Error creating provider: %v", err)
+		}
+
+		logger.Info("Sorting %d commits...", len(commits))
+		entries, err := changelogEntries(cmd.Context(), provider, commits, cfg.Lint.Types)
+		checkGenerateError(err)
+
+		release := changelog.Release{
+			Version: changelogRelease,
+			Date:    changelogDate,
+			Entries: entries,
+		}
+		if release.Date == "" && release.Version != "Unreleased" {
+			release.Date = time.Now().Format(time.DateOnly)
+		}
+
+		if !changelogWrite && !cmd.Flags().Changed("file") {
+			fmt.Print(release.Markdown())
+			return
+		}
+
+		existing, err := os.ReadFile(changelogFile)
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			logger.Fatal("Error reading %s: %v", changelogFile, err)
+		}
+		updated := changelog.Insert(string(existing), release.Markdown())
+		if err := os.WriteFile(changelogFile, []byte(updated), 0o644); err != nil {
+			logger.Fatal("Error writing %s: %v", changelogFile, err)
+		}
+		logger.Success("Release %s added to %s", release.Version, changelogFile)
+	},
+}
+
+// changelogEntries sorts the commits into the sections of the changelog, the
+// request can be cancelled with Ctrl-C
+func changelogEntries(ctx context.Context, provider llm.Provider, commits []git.Commit, types []string) ([]changelog.Entry, error) {
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt)
+	defer stop()
+
+	return changelog.Entries(ctx, provider, commits, types)
+}
+
+func init() {
+	changelogCmd.Flags().StringVar(&changelogRelease, "release", "Unreleased", "Version of the release")
+	changelogCmd.Flags().StringVar(&changelogDate, "date", "", "Date of the release (default is today, omitted for Unreleased)")
+	changelogCmd.Flags().BoolVarP(&changelogWrite, "write", "w", false, "Insert the release into the changelog file instead of printing it")
+	changelogCmd.Flags().StringVarP(&changelogFile, "file", "f", "CHANGELOG.md", "Changelog file to insert the release into, implies --write")
+	rootCmd.AddCommand(changelogCmd)
+}
@@ -0,0 +1,334 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"slices"
+	"strconv"
+
+	"github.com/madflow/kommit/internal/cleanup"
+	"github.com/madflow/kommit/internal/config"
+	"github.com/madflow/kommit/internal/diff"
+	"github.com/madflow/kommit/internal/git"
+	"github.com/madflow/kommit/internal/lint"
+	"github.com/madflow/kommit/internal/llm"
+	"github.com/madflow/kommit/internal/logger"
+	"github.com/madflow/kommit/internal/prompt"
+	"github.com/madflow/kommit/internal/redact"
+	"github.com/madflow/kommit/internal/structured"
+	"github.com/madflow/kommit/internal/style"
+	"github.com/madflow/kommit/internal/summarize"
+)
+
+// prepareRequest completes the request for generating the message with the rules
+// and the diff. The diff is fitted into the context window of the model, summarizing
+// it per file if configured. The summaries can be cancelled with Ctrl-C. Rules and
+// prompts of the configuration containing template actions are rendered.
+func prepareRequest(ctx context.Context, cfg *config.Config, provider llm.Provider, gitDiff string, req *llm.Request) error {
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt)
+	defer stop()
+
+	gitDiff, excluded, err := sanitizeDiff(cfg, gitDiff)
+	if err != nil {
+		return err
+	}
+
+	templated := prompt.IsTemplate(cfg.Rules) || cfg.Prompt.System != "" || cfg.Prompt.User != ""
+	var data *prompt.Data
+	if templated {
+		data = templateData(cfg, req.RepoCtx)
+	}
+
+	req.Rules, err = prompt.Render("rules", cfg.Rules, data)
+	if err != nil {
+		return err
+	}
+	if templated {
+		// Render the prompts without the diff to measure the space left for it
+		data.Rules = req.Rules
+		if err := renderPrompts(cfg, req, data); err != nil {
+			return err
+		}
+	}
+	if cfg.Structured.Enabled {
+		var types []string
+		if cfg.Lint.Conventional {
+			types = cfg.Lint.Types
+		}
+		req.Format = structured.Schema(types)
+	}
+	var promptDiff string
+	budget := prompt.DiffBudget(cfg.Diff.ContextSize, cfg.Diff.ResponseTokens, req)
+	if summarize.Enabled(cfg.Summarize.Mode, diff.EstimateTokens(gitDiff), budget) {
+		logger.Info("Summarizing changes per file...")
+		summaryBudget := prompt.SummaryBudget(cfg.Diff.ContextSize, cfg.Diff.ResponseTokens)
+		excludedNote := diff.ExcludedNote(excluded)
+		summaries, omitted, err := summarize.Diff(ctx, provider, gitDiff, summaryBudget, budget-diff.EstimateTokens(excludedNote), cfg.Summarize.Concurrency)
+		if err != nil {
+			return fmt.Errorf("failed to summarize diff: %w", err)
+		}
+		if len(omitted) > 0 {
+			logger.Warning("Summaries shortened to fit the context window (%d files omitted)", len(omitted))
+		}
+		promptDiff = summaries + excludedNote
+	} else {
+		fitted := diff.Fit(gitDiff, budget)
+		if len(fitted.Trimmed) > 0 || len(fitted.Omitted) > 0 {
+			logger.Warning("Diff shortened to fit the context window (%d files trimmed, %d files omitted)", len(fitted.Trimmed), len(fitted.Omitted))
+		}
+		fitted.Excluded = excluded
+		promptDiff = fitted.String()
+	}
+
+	req.Diff = promptDiff
+	if templated {
+		data.Diff = promptDiff
+		return renderPrompts(cfg, req, data)
+	}
+	return nil
+}
+
+// templateData collects the information about the repository available to the
+// templates of the rules and the prompts
+func templateData(cfg *config.Config, repoCtx *git.RepoContext) *prompt.Data {
+	data := prompt.NewData(repoCtx)
+	data.UserName, data.UserEmail = git.GetUser()
+
+	// A repository without commits has no history
+	if cfg.Prompt.RecentCommits > 0 {
+		if commits, err := git.GetCommits("-n", strconv.Itoa(cfg.Prompt.RecentCommits), "HEAD"); err == nil {
+			for i := len(commits) - 1; i >= 0; i-- {
+				data.RecentCommits = append(data.RecentCommits, commits[i].Subject())
+			}
+		}
+	}
+	return data
+}
+
+// styleExamples returns the latest commit messages up to rev as examples of the
+// style of the repository if configured. Commands rewriting commits start before
+// them, so that the messages being replaced are no examples. An empty rev, e.g.
+// the parent of a root commit, has no examples.
+func styleExamples(cfg *config.Config, rev string) []string {
+	if cfg.Examples.Count <= 0 || rev == "" {
+		return nil
+	}
+
+	// Read more commits than needed since some are filtered out
+	logArgs := []string{"-n", strconv.Itoa(cfg.Examples.Count * 5)}
+	if cfg.Examples.SkipMerges {
+		logArgs = append(logArgs, "--no-merges")
+	}
+	commits, err := git.GetCommits(append(logArgs, rev)...)
+	if err != nil {
+		return nil
+	}
+	return style.Examples(commits, cfg.Examples.Count, style.Filter{
+		SkipMerges:  cfg.Examples.SkipMerges,
+		SkipAuthors: cfg.Examples.SkipAuthors,
+	})
+}
+
+// renderPrompts renders the system and the user prompt templates of the configuration into the request
+func renderPrompts(cfg *config.Config, req *llm.Request, data *prompt.Data) error {
+	var err error
+	if req.System, err = prompt.Render("system prompt", cfg.Prompt.System, data); err != nil {
+		return err
+	}
+	req.User, err = prompt.Render("user prompt", cfg.Prompt.User, data)
+	return err
+}
+
+// sanitizeDiff removes the excluded files from the diff and masks secrets. It
+// returns the diff and the paths of the excluded files.
+func sanitizeDiff(cfg *config.Config, gitDiff string) (string, []string, error) {
+	// Hide lockfiles, generated and vendored files from the model
+	gitDiff, excluded := diff.Filter(gitDiff, cfg.Diff.Include, cfg.Diff.Exclude)
+
+	// Mask secrets before the diff leaves the machine
+	gitDiff, err := redactSecrets(cfg, gitDiff)
+	if err != nil {
+		return "", nil, err
+	}
+	return gitDiff, excluded, nil
+}
+
+// generate generates a single commit message, the request can be cancelled with Ctrl-C.
+// Wrappers like code fences are removed from the message. Messages violating the lint
+// rules and structured answers that cannot be parsed are sent back to the model with
+// the problems up to validate.retries times.
+func generate(ctx context.Context, cfg *config.Config, provider llm.Provider, req *llm.Request) (string, error) {
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt)
+	defer stop()
+
+	answer, err := provider.GenerateCommitMessage(ctx, req)
+	if err != nil {
+		return "", err
+	}
+	message, violations, err := render(cfg, req, answer)
+	if err != nil {
+		return "", err
+	}
+
+	// Repair on a copy so that the history of the request is not changed
+	repairReq := *req
+	repairReq.History = slices.Clip(req.History)
+	for attempt := 0; len(violations) > 0 && attempt < cfg.Validate.Retries; attempt++ {
+		logger.Warning("Generated message has %d problems, asking the model to fix them (attempt %d of %d)", len(violations), attempt+1, cfg.Validate.Retries)
+		repairReq.History = append(repairReq.History,
+			llm.Message{Role: llm.RoleAssistant, Content: answer},
+			llm.Message{Role: llm.RoleUser, Content: prompt.Violations(problems(violations))},
+		)
+		answer, err = provider.GenerateCommitMessage(ctx, &repairReq)
+		if err != nil {
+			return "", err
+		}
+		if message, violations, err = render(cfg, req, answer); err != nil {
+			return "", err
+		}
+	}
+
+	for _, violation := range violations {
+		if violation.Rule == ruleStructured {
+			return "", fmt.Errorf("failed to read the answer of the model: %s", violation.Message)
+		}
+		logger.Warning("Generated message: %s", violation)
+	}
+	return message, nil
+}
+
+// ruleStructured names the violation of a structured answer that cannot be parsed
+const ruleStructured = "structured"
+
+// render turns the answer of the model into the commit message and returns its
+// violations of the lint rules. Structured answers are rendered with the configured
+// template, an answer that cannot be parsed is a violation. Wrappers are removed
+// from plain text.
+func render(cfg *config.Config, req *llm.Request, answer string) (string, []lint.Violation, error) {
+	message := cleanup.Message(answer)
+	if len(req.Format) > 0 {
+		parsed, err := structured.Parse(answer)
+		if errors.Is(err, structured.ErrInvalidAnswer) {
+			return "", []lint.Violation{{
+				Rule:    ruleStructured,
+				Message: fmt.Sprintf("answer must be a JSON object with the fields type, scope, subject, body, breaking and footers (%v)", err),
+			}}, nil
+		}
+		if err != nil {
+			return "", nil, err
+		}
+		if message, err = structured.Render(cfg.Structured.Template, parsed); err != nil {
+			return "", nil, err
+		}
+	}
+	return message, lint.Check(message, cfg.Lint), nil
+}
+
+// streamOutput prints the tokens while the message is generated if the provider
+// supports it. Structured answers are not streamed, the JSON is not the message.
+func streamOutput(cfg *config.Config, provider llm.Provider) {
+	if streamer, ok := provider.(llm.Streamer); ok && !cfg.Structured.Enabled {
+		streamer.SetStreamOutput(os.Stdout)
+	}
+}
+
+// problems returns the descriptions of the violations
+func problems(violations []lint.Violation) []string {
+	descriptions := make([]string, 0, len(violations))
+	for _, violation := range violations {
+		descriptions = append(descriptions, violation.Message)
+	}
+	return descriptions
+}
+
+// generateCandidates generates n commit messages with different sampling options
+func generateCandidates(ctx context.Context, cfg *config.Config, provider llm.Provider, req *llm.Request, n int) ([]string, error) {
+	if n <= 1 {
+		logger.Info("Analyzing changes...")
+		message, err := generate(ctx, cfg, provider, req)
+		return []string{message}, err
+	}
+
+	messages := make([]string, 0, n)
+	for i := range n {
+		logger.Info("Generating candidate %d of %d...", i+1, n)
+		candidateReq := *req
+		candidateReq.Options = llm.CandidateOptions(i)
+		message, err := generate(ctx, cfg, provider, &candidateReq)
+		if err != nil {
+			return nil, err
+		}
+		messages = append(messages, message)
+	}
+	return messages, nil
+}
+
+// generator produces new versions of the commit message at the confirmation prompt
+type generator struct {
+	ctx      context.Context
+	cfg      *config.Config
+	provider llm.Provider
+	req      *llm.Request
+	attempt  int
+}
+
+// regenerate generates a new message from scratch with a higher temperature and a new seed
+func (g *generator) regenerate() (string, error) {
+	g.req.History = nil
+	g.req.Options = llm.CandidateOptions(g.attempt)
+	g.attempt++
+
+	logger.Info("Regenerating commit message...")
+	return generate(g.ctx, g.cfg, g.provider, g.req)
+}
+
+// refine sends the current message and the feedback of the user back to the
+// model, keeping the previous turns of the conversation
+func (g *generator) refine(message, feedback string) (string, error) {
+	g.req.History = append(g.req.History,
+		llm.Message{Role: llm.RoleAssistant, Content: message},
+		llm.Message{Role: llm.RoleUser, Content: prompt.Feedback(feedback)},
+	)
+
+	logger.Info("Refining commit message...")
+	refined, err := generate(g.ctx, g.cfg, g.provider, g.req)
+	if err != nil {
+		// Drop the failed turn so that it can be retried
+		g.req.History = g.req.History[:len(g.req.History)-2]
+	}
+	return refined, err
+}
+
+// checkGenerateError exits if generating the commit message failed or was cancelled
+func checkGenerateError(err error) {
+	if errors.Is(err, context.Canceled) {
+		logger.Fatal("Commit message generation cancelled by user")
+	}
+	if err != nil {
+		logger.Fatal("Error generating commit message: %v", err)
+	}
+}
+
+// redactSecrets masks secrets in the diff and warns about them, or aborts if configured
+func redactSecrets(cfg *config.Config, gitDiff string) (string, error) {
+	if !cfg.Redact.Enabled {
+		return gitDiff, nil
+	}
+
+	redactor, err := redact.New(cfg.Redact.Patterns)
+	if err != nil {
+		return "", err
+	}
+
+	redacted, findings := redactor.Redact(gitDiff)
+	for _, finding := range findings {
+		logger.Warning("Possible secret in %s (%s) has been redacted", finding.File, finding.Rule)
+	}
+	if len(findings) > 0 && cfg.Redact.OnDetect == redact.OnDetectAbort {
+		return "", fmt.Errorf("staged changes contain %d possible secrets, aborting", len(findings))
+	}
+	return redacted, nil
+}
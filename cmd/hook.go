@@ -0,0 +1,146 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/madflow/kommit/internal/config"
+	"github.com/madflow/kommit/internal/git"
+	"github.com/madflow/kommit/internal/hook"
+	"github.com/madflow/kommit/internal/llm"
+	"github.com/madflow/kommit/internal/logger"
+	"github.com/spf13/cobra"
+)
+
+var hookForce bool
+
+// hookCmd groups the commands managing the prepare-commit-msg hook
+var hookCmd = &cobra.Command{
+	Use:   "hook",
+	Short: "Manage the prepare-commit-msg git hook",
+	Long: `Manage the prepare-commit-msg git hook.
+
+The hook generates a commit message whenever git commit is run without a
+message (from the command line or an IDE), so the editor opens pre-filled.
+Merges, amends and commits with -m or -F are left untouched.`,
+}
+
+// hookInstallCmd installs the hook into the current repository
+var hookInstallCmd = &cobra.Command{
+	Use:   "install",
+	Short: "Install the prepare-commit-msg hook",
+	Long:  "Install the prepare-commit-msg hook into the hooks directory of the current repository (honoring core.hooksPath).",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		hooksDir := hooksDirOrFatal()
+
+		executable, err := os.Executable()
+		if err != nil {
+			executable = "kommit"
+		}
+
+		path, err := hook.Install(hooksDir, executable, hookForce)
+		if err != nil {
+			logger.Fatal("Error installing hook: %v", err)
+		}
+		logger.Success("Installed %s", path)
+	},
+}
+
+// hookUninstallCmd removes the hook from the current repository
+var hookUninstallCmd = &cobra.Command{
+	Use:   "uninstall",
+	Short: "Uninstall the prepare-commit-msg hook",
+	Long:  "Remove the prepare-commit-msg hook from the current repository if it was installed by kommit.",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		path, err := hook.Uninstall(hooksDirOrFatal())
+		if err != nil {
+			logger.Fatal("Error uninstalling hook: %v", err)
+		}
+		logger.Success("Removed %s", path)
+	},
+}
+
+// hookRunCmd is the entrypoint called by the installed hook
+var hookRunCmd = &cobra.Command{
+	Use:   "run <message-file> [source] [commit]",
+	Short: "Fill the commit message file (called by the prepare-commit-msg hook)",
+	Long: `Fill the commit message file with a generated message.
+
+This is called by the installed prepare-commit-msg hook with the arguments git
+passes to the hook. Errors are reported as warnings and never block the commit.`,
+	Args:   cobra.RangeArgs(1, 3),
+	Hidden: true,
+	Run: func(cmd *cobra.Command, args []string) {
+		messageFile := args[0]
+		source := ""
+		if len(args) > 1 {
+			source = args[1]
+		}
+
+		content, err := os.ReadFile(messageFile)
+		if err != nil {
+			logger.Warning("kommit: %v", err)
+			return
+		}
+		existing, err := git.StripComments(string(content))
+		if err != nil {
+			logger.Warning("kommit: %v", err)
+			return
+		}
+		if !hook.ShouldFill(source, existing) {
+			return
+		}
+
+		gitDiff, err := git.GetGitDiff()
+		if err != nil || gitDiff == "" {
+			return
+		}
+		repoCtx, err := git.GetRepoContext()
+		if err != nil {
+			logger.Warning("kommit: error getting repository context: %v", err)
+			return
+		}
+
+		cfg := config.Get()
+		provider, err := llm.New(cfg)
+		if err != nil {
+			logger.Warning("kommit: error creating provider: %v", err)
+			return
+		}
+
+		logger.Info("kommit: generating commit message...")
+		req := &llm.Request{RepoCtx: repoCtx, Examples: styleExamples(cfg, "HEAD")}
+		if err := prepareRequest(cmd.Context(), cfg, provider, gitDiff, req); err != nil {
+			logger.Warning("kommit: error generating commit message: %v", err)
+			return
+		}
+		message, err := generate(cmd.Context(), cfg, provider, req)
+		if err != nil {
+			logger.Warning("kommit: error generating commit message: %v", err)
+			return
+		}
+
+		if err := hook.FillMessage(messageFile, message); err != nil {
+			logger.Warning("kommit: %v", err)
+		}
+	},
+}
+
+// hooksDirOrFatal returns the hooks directory of the current repository or exits
+func hooksDirOrFatal() string {
+	if !git.IsGitRepo() {
+		logger.Fatal("Not in a git repository")
+	}
+	hooksDir, err := git.GetHooksDir()
+	if err != nil {
+		logger.Fatal("Error locating hooks directory: %v", err)
+	}
+	return hooksDir
+}
+
+func init() {
+	hookInstallCmd.Flags().BoolVarP(&hookForce, "force", "f", false, "Replace an existing prepare-commit-msg hook")
+	hookCmd.AddCommand(hookInstallCmd, hookUninstallCmd, hookRunCmd)
+	rootCmd.AddCommand(hookCmd)
+}
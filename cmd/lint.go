@@ -0,0 +1,99 @@
+package cmd
+
+import (
+	"os"
+	"strings"
+
+	"github.com/madflow/kommit/internal/config"
+	"github.com/madflow/kommit/internal/git"
+	"github.com/madflow/kommit/internal/lint"
+	"github.com/madflow/kommit/internal/logger"
+	"github.com/spf13/cobra"
+)
+
+// lintCmd checks commit messages against the configured rules
+var lintCmd = &cobra.Command{
+	Use:   "lint [rev-range|file]",
+	Short: "Check commit messages against the configured rules",
+	Long: `Check commit messages against the lint rules of the configuration.
+
+The checks cover the length of the subject, the blank line after the subject,
+the imperative mood, trailing periods and optionally the conventional commits
+format with allowed types and scopes. Messages generated by git (merges,
+reverts, fixup! and squash! commits) are not checked.
+
+The argument is a file with a commit message, a revision range like
+origin/main..HEAD or a single commit (default HEAD). The command exits with a
+non-zero status if any message has problems, so it can be used in CI and as a
+commit-msg hook:
+
+  printf '#!/bin/sh\nexec kommit lint "$1"\n' > .git/hooks/commit-msg
+  chmod +x .git/hooks/commit-msg`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		target := "HEAD"
+		if len(args) > 0 {
+			target = args[0]
+		}
+		rules := config.Get().Lint
+
+		// A commit message file, e.g. from the commit-msg hook
+		if content, err := os.ReadFile(target); err == nil {
+			message, err := git.StripComments(string(content))
+			if err != nil {
+				logger.Fatal("Error reading %s: %v", target, err)
+			}
+			if violations := lint.Check(message, rules); len(violations) > 0 {
+				reportViolations(firstLine(message), violations)
+				logger.Fatal("Commit message has %d problems", len(violations))
+			}
+			return
+		}
+
+		if !git.IsGitRepo() {
+			logger.Fatal("Not in a git repository")
+		}
+
+		logArgs := []string{"--no-merges", target}
+		if !strings.Contains(target, "..") {
+			logArgs = []string{"-1", target}
+		}
+		commits, err := git.GetCommits(logArgs...)
+		if err != nil {
+			logger.Fatal("Error reading commits: %v", err)
+		}
+
+		failed := 0
+		for _, commit := range commits {
+			violations := lint.Check(commit.Message, rules)
+			if len(violations) == 0 {
+				continue
+			}
+			failed++
+			reportViolations(shortHash(commit.Hash)+" "+commit.Subject(), violations)
+		}
+
+		if failed > 0 {
+			logger.Fatal("%d of %d commit messages have problems", failed, len(commits))
+		}
+		logger.Success("%d commit messages checked, no problems found", len(commits))
+	},
+}
+
+// reportViolations prints the violations of a commit message
+func reportViolations(title string, violations []lint.Violation) {
+	logger.Error("%s", title)
+	for _, violation := range violations {
+		logger.Warning("%s", violation)
+	}
+}
+
+// firstLine returns the first line of the text
+func firstLine(text string) string {
+	line, _, _ := strings.Cut(text, "\n")
+	return line
+}
+
+func init() {
+	rootCmd.AddCommand(lintCmd)
+}
@@ -0,0 +1,107 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+
+	"github.com/madflow/kommit/internal/config"
+	"github.com/madflow/kommit/internal/diff"
+	"github.com/madflow/kommit/internal/git"
+	"github.com/madflow/kommit/internal/llm"
+	"github.com/madflow/kommit/internal/logger"
+	"github.com/madflow/kommit/internal/pr"
+	"github.com/madflow/kommit/internal/prompt"
+	"github.com/spf13/cobra"
+)
+
+var prOutput string
+
+// prCmd generates the title and description of a pull request
+var prCmd = &cobra.Command{
+	Use:   "pr [base]",
+	Short: "Generate a pull request title and description",
+	Long: `Generate the title and markdown description of a pull request for the current branch.
+
+The commits and the diff between the merge-base with the base branch and HEAD
+are sent to the model together with the pr.rules of the configuration. The base
+branch defaults to the default branch of origin, or main or master.
+
+The title is written on the first line followed by a blank line and the
+description, to stdout or to the file given with --output. Log messages go to
+stderr, so the output can be piped into any pull request tool, e.g.
+
+  kommit pr -o PR.md
+  gh pr create --title "$(head -n1 PR.md)" --body "$(tail -n +3 PR.md)"`,
+	Args:        cobra.MaximumNArgs(1),
+	Annotations: map[string]string{stdoutAnnotation: ""},
+	Run: func(cmd *cobra.Command, args []string) {
+		if !git.IsGitRepo() {
+			logger.Fatal("Not in a git repository")
+		}
+
+		base, mergeBase, commits := branchCommits(args)
+		if len(commits) == 0 {
+			logger.Fatal("No commits since %s", base)
+		}
+
+		repoCtx, err := git.GetRepoContextForDiff(mergeBase, "HEAD")
+		if err != nil {
+			logger.Fatal("Error getting repository context: %v", err)
+		}
+		gitDiff, err := git.GetDiff(mergeBase, "HEAD")
+		if err != nil {
+			logger.Fatal("Error getting git diff: %v", err)
+		}
+
+		cfg := config.Get()
+		provider, err := llm.New(cfg)
+		if err != nil {
+			logger.Fatal("Error creating provider: %v", err)
+		}
+
+		messages := make([]string, 0, len(commits))
+		for _, commit := range commits {
+			messages = append(messages, commit.Message)
+		}
+
+		logger.Info("Describing %d commits since %s...", len(commits), base)
+		description, err := describePR(cmd.Context(), cfg, provider, gitDiff, messages, repoCtx)
+		checkGenerateError(err)
+
+		if prOutput == "" {
+			fmt.Println(description)
+			return
+		}
+		if err := os.WriteFile(prOutput, []byte(description.String()+"\n"), 0o644); err != nil {
+			logger.Fatal("Error writing %s: %v", prOutput, err)
+		}
+		logger.Success("Pull request description written to %s", prOutput)
+	},
+}
+
+// describePR asks the model for the title and description of the pull request,
+// the request can be cancelled with Ctrl-C
+func describePR(ctx context.Context, cfg *config.Config, provider llm.Provider, gitDiff string, commits []string, repoCtx *git.RepoContext) (pr.Description, error) {
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt)
+	defer stop()
+
+	gitDiff, excluded, err := sanitizeDiff(cfg, gitDiff)
+	if err != nil {
+		return pr.Description{}, err
+	}
+	fitted := diff.Fit(gitDiff, prompt.PRBudget(cfg.Diff.ContextSize, cfg.Diff.ResponseTokens, cfg.PR.Rules, commits, repoCtx))
+	fitted.Excluded = excluded
+
+	answer, err := provider.Complete(ctx, prompt.PRSystem(cfg.PR.Rules), prompt.PRUser(fitted.String(), commits, repoCtx))
+	if err != nil {
+		return pr.Description{}, err
+	}
+	return pr.Parse(answer), nil
+}
+
+func init() {
+	prCmd.Flags().StringVarP(&prOutput, "output", "o", "", "Write the title and description to a file instead of stdout")
+	rootCmd.AddCommand(prCmd)
+}
@@ -0,0 +1,155 @@
+package cmd
+
+import (
+	"context"
+	"strings"
+
+	"github.com/madflow/kommit/internal/config"
+	"github.com/madflow/kommit/internal/git"
+	"github.com/madflow/kommit/internal/llm"
+	"github.com/madflow/kommit/internal/logger"
+	"github.com/spf13/cobra"
+)
+
+// rewordCmd generates new messages for existing commits and rewrites them
+var rewordCmd = &cobra.Command{
+	Use:   "reword <rev-range>",
+	Short: "Generate new messages for existing commits",
+	Long: `Generate new messages for the commits in a revision range and rewrite them.
+
+Every commit gets a message generated from its own diff. The old and new
+message are shown side by side and each one has to be accepted. The accepted
+messages are applied with an interactive rebase, the content of the commits
+is not changed.
+
+The range is given like for git log, e.g. main..HEAD. A single revision
+selects the commits after it up to HEAD, so "kommit reword HEAD~3" rewords
+the last three commits. The range must not contain merge commits.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if !git.IsGitRepo() {
+			logger.Fatal("Not in a git repository")
+		}
+
+		// The rebase needs a clean working tree
+		hasChanges, err := git.HasAnyChanges()
+		if err != nil {
+			logger.Fatal("Error checking for changes: %v", err)
+		}
+		if hasChanges {
+			logger.Fatal("Please commit or stash your changes before rewording commits")
+		}
+
+		revRange := args[0]
+		if !strings.Contains(revRange, "..") {
+			revRange += "..HEAD"
+		}
+
+		commits, err := git.GetCommits(revRange)
+		if err != nil {
+			logger.Fatal("Error reading commits: %v", err)
+		}
+		if len(commits) == 0 {
+			logger.Success("No commits to reword")
+			return
+		}
+		for _, commit := range commits {
+			if commit.IsMerge() {
+				logger.Fatal("Cannot reword merge commit %s", shortHash(commit.Hash))
+			}
+		}
+		if !git.IsAncestor(commits[len(commits)-1].Hash, "HEAD") {
+			logger.Fatal("The commits of %s are not part of the current branch", revRange)
+		}
+
+		// Rebase onto the parent of the oldest commit, a root commit rewrites the whole history
+		base := ""
+		if len(commits[0].Parents) > 0 {
+			base = commits[0].Parents[0]
+		}
+
+		cfg := config.Get()
+		provider, err := llm.New(cfg)
+		if err != nil {
+			logger.Fatal("Error creating provider: %v", err)
+		}
+		streamOutput(cfg, provider)
+
+		// The messages being reworded are no examples of the style
+		examples := styleExamples(cfg, base)
+		messages := make(map[string]string)
+		for i, commit := range commits {
+			logger.Println("================================")
+			logger.Printf("🔖 Commit %d of %d: %s\n\n", i+1, len(commits), shortHash(commit.Hash))
+
+			message, ok := rewordCommit(cmd.Context(), cfg, provider, commit, examples)
+			if !ok {
+				logger.Info("Keeping the current message")
+				continue
+			}
+			if message != commit.Message {
+				messages[commit.Hash] = message
+			}
+		}
+
+		if len(messages) == 0 {
+			logger.Success("No commit messages changed")
+			return
+		}
+
+		logger.Info("Rewording %d commits...", len(messages))
+		if err := git.RewordCommits(base, messages); err != nil {
+			logger.Fatal("Error rewording commits: %v", err)
+		}
+		logger.Success("Commits reworded successfully!")
+	},
+}
+
+// rewordCommit generates a new message for the commit from its diff and asks the
+// user to accept it. It returns the new message and whether it was accepted.
+func rewordCommit(ctx context.Context, cfg *config.Config, provider llm.Provider, commit git.Commit, examples []string) (string, bool) {
+	parent, err := git.GetParent(commit.Hash)
+	if err != nil {
+		logger.Fatal("Error reading commit %s: %v", shortHash(commit.Hash), err)
+	}
+	gitDiff, err := git.GetDiff(parent, commit.Hash)
+	if err != nil {
+		logger.Fatal("Error getting git diff: %v", err)
+	}
+	repoCtx, err := git.GetRepoContextForDiff(parent, commit.Hash)
+	if err != nil {
+		logger.Fatal("Error getting repository context: %v", err)
+	}
+
+	logger.Println("📜 Current Commit Message:")
+	logger.Printf("%s\n\n", commit.Message)
+
+	req := &llm.Request{RepoCtx: repoCtx, Examples: examples}
+	checkGenerateError(prepareRequest(ctx, cfg, provider, gitDiff, req))
+	messages, err := generateCandidates(ctx, cfg, provider, req, 1)
+	checkGenerateError(err)
+
+	logger.Println("\n📝 Generated Commit Message:")
+	logger.Printf("%s\n\n", messages[0])
+
+	gen := &generator{
+		ctx:      ctx,
+		cfg:      cfg,
+		provider: provider,
+		req:      req,
+		attempt:  1,
+	}
+	return confirmMessage("Do you want to use the new message?", messages[0], gen)
+}
+
+// shortHash abbreviates a commit hash for display
+func shortHash(hash string) string {
+	if len(hash) > 7 {
+		return hash[:7]
+	}
+	return hash
+}
+
+func init() {
+	rootCmd.AddCommand(rewordCmd)
+}
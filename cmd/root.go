@@ -3,19 +3,26 @@ package cmd
 import (
 	"bufio"
 	"os"
+	"strconv"
 	"strings"
 
 	"github.com/madflow/kommit/internal/config"
 	"github.com/madflow/kommit/internal/git"
+	"github.com/madflow/kommit/internal/llm"
 	"github.com/madflow/kommit/internal/logger"
-	"github.com/madflow/kommit/internal/ollama"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
+
+	// Register the available LLM providers
+	_ "github.com/madflow/kommit/internal/ollama"
+	_ "github.com/madflow/kommit/internal/openai"
 )
 
 var (
-	cfgFile string
-	yolo    bool
+	cfgFile    string
+	yolo       bool
+	candidates int
+	amend      bool
 )
 
 type CommitMessage struct {
@@ -39,10 +46,20 @@ func yoloCommit(message string) {
 	logger.Success("Changes committed and pushed successfully!")
 }
 
+// stdoutAnnotation marks commands that write their result to stdout. Their log
+// messages go to stderr so that the result can be piped into other tools.
+const stdoutAnnotation = "stdout"
+
 // rootCmd represents the base command when called without any subcommands
 var rootCmd = &cobra.Command{
 	Use:   "kommit",
 	Short: "Git commits for the rest of us",
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		if _, ok := cmd.Annotations[stdoutAnnotation]; ok {
+			logger.SetOutput(os.Stderr)
+		}
+		initConfig()
+	},
 	Run: func(cmd *cobra.Command, args []string) {
 		logger.Println("🤖 Kommit")
 		logger.Println("================================")
@@ -59,59 +76,69 @@ var rootCmd = &cobra.Command{
 			}
 		}
 
-		// Check for staged changes to commit
-		hasChanges, err := git.HasStagedChanges()
-		if err != nil {
-			logger.Fatal("Error checking for changes: %v", err)
-		}
+		// Diff of the staged changes, or of the last commit plus the staged changes when amending
+		diffArgs := []string{"--cached"}
+		examplesRev := "HEAD"
+		if amend {
+			parent, err := git.GetParent("HEAD")
+			if err != nil {
+				logger.Fatal("Nothing to amend: %v", err)
+			}
+			diffArgs = append(diffArgs, parent)
+			examplesRev = parent
+		} else {
+			// Check for staged changes to commit
+			hasChanges, err := git.HasStagedChanges()
+			if err != nil {
+				logger.Fatal("Error checking for changes: %v", err)
+			}
 
-		if !hasChanges {
-			logger.Success("No changes to commit")
-			return
+			if !hasChanges {
+				logger.Success("No changes to commit")
+				return
+			}
 		}
 
 		// Get repository context
-		repoCtx, err := git.GetRepoContext()
+		repoCtx, err := git.GetRepoContextForDiff(diffArgs...)
 		if err != nil {
 			logger.Fatal("Error getting repository context: %v", err)
 		}
-
-		// Display repository context
-		logger.Println("📊 Repository Context:")
-		logger.Printf("Branch: %s\n", repoCtx.BranchName)
-		logger.Printf("Files changed: %d\n", repoCtx.FilesChanged)
-
-		if repoCtx.FilesChanged > 0 {
-			logger.Println("\n📝 Change Summary:")
-			logger.Println(repoCtx.ChangeSummary)
-
-			if len(repoCtx.FileChanges) > 0 {
-				logger.Println("\n📋 File Changes:")
-				for _, change := range repoCtx.FileChanges {
-					logger.Printf("[%s] %s (%s)\n", change.Status, change.FilePath, change.FileType)
-				}
-			}
-		}
-
-		logger.Println()
+		displayRepoContext(repoCtx)
 
 		// Get git diff for AI analysis
-		diff, err := git.GetGitDiff()
+		gitDiff, err := git.GetDiff(diffArgs...)
 		if err != nil {
 			logger.Fatal("Error getting git diff: %v", err)
 		}
 
-		logger.Info("Analyzing changes...")
-
-		// Generate commit message using Ollama
+		// Generate commit message using the configured provider
 		cfg := config.Get()
-		ollamaClient := ollama.NewClient(&cfg.Ollama)
-		messageText, err := ollamaClient.GenerateCommitMessage(diff, cfg.Rules, repoCtx)
+		provider, err := llm.New(cfg)
 		if err != nil {
-			logger.Fatal("Error generating commit message: %v", err)
+			logger.Fatal("Error creating provider: %v", err)
+		}
+		// Streaming several candidates would be hard to follow
+		if candidates <= 1 {
+			streamOutput(cfg, provider)
 		}
+
+		req := &llm.Request{RepoCtx: repoCtx, Examples: styleExamples(cfg, examplesRev)}
+		checkGenerateError(prepareRequest(cmd.Context(), cfg, provider, gitDiff, req))
+
+		messages, err := generateCandidates(cmd.Context(), cfg, provider, req, candidates)
+		checkGenerateError(err)
+
 		message := &CommitMessage{
-			Message: strings.TrimSpace(messageText),
+			Message: messages[0],
+		}
+		if len(messages) > 1 && !yolo {
+			selected, ok := selectCandidate(messages)
+			if !ok {
+				logger.Error("Commit cancelled by user")
+				return
+			}
+			message.Message = selected
 		}
 
 		// Display generated message
@@ -121,12 +148,30 @@ var rootCmd = &cobra.Command{
 		if yolo {
 			yoloCommit(message.Message)
 		} else {
+			gen := &generator{
+				ctx:      cmd.Context(),
+				cfg:      cfg,
+				provider: provider,
+				req:      req,
+				attempt:  len(messages),
+			}
+
 			// Ask user for confirmation in non-yolo mode
-			if !askForConfirmation() {
+			var confirmed bool
+			message.Message, confirmed = confirmMessage("Do you want to commit with this message?", message.Message, gen)
+			if !confirmed {
 				logger.Error("Commit cancelled by user")
 				return
 			}
 
+			if amend {
+				if err := git.AmendCommit(message.Message); err != nil {
+					logger.Fatal("Error amending commit: %v", err)
+				}
+				logger.Success("Last commit amended successfully!")
+				return
+			}
+
 			// Commit the changes
 			if err := git.CommitChanges(message.Message); err != nil {
 				logger.Fatal("Error committing changes: %v", err)
@@ -137,6 +182,27 @@ var rootCmd = &cobra.Command{
 	},
 }
 
+// displayRepoContext prints the branch and the changed files
+func displayRepoContext(repoCtx *git.RepoContext) {
+	logger.Println("📊 Repository Context:")
+	logger.Printf("Branch: %s\n", repoCtx.BranchName)
+	logger.Printf("Files changed: %d\n", repoCtx.FilesChanged)
+
+	if repoCtx.FilesChanged > 0 {
+		logger.Println("\n📝 Change Summary:")
+		logger.Println(repoCtx.ChangeSummary)
+
+		if len(repoCtx.FileChanges) > 0 {
+			logger.Println("\n📋 File Changes:")
+			for _, change := range repoCtx.FileChanges {
+				logger.Printf("[%s] %s (%s)\n", change.Status, change.FilePath, change.FileType)
+			}
+		}
+	}
+
+	logger.Println()
+}
+
 func Execute() {
 	err := rootCmd.Execute()
 	if err != nil {
@@ -144,18 +210,123 @@ func Execute() {
 	}
 }
 
-func askForConfirmation() bool {
-	reader := bufio.NewReader(os.Stdin)
-	logger.Printf("Do you want to commit with this message? [y/N] ")
-	text, _ := reader.ReadString('\n')
-	text = strings.TrimSpace(strings.ToLower(text))
-	return text == "y" || text == "yes"
+// Choices at the confirmation prompt
+const (
+	choiceCancel = iota
+	choiceCommit
+	choiceEdit
+	choiceRegenerate
+	choiceRefine
+)
+
+// stdin is shared by all prompts so that buffered input is not lost between them
+var stdin = bufio.NewReader(os.Stdin)
+
+// confirmMessage asks the user to accept, edit, regenerate, refine or reject the message
+// until it is accepted. It returns the final message and whether it was accepted.
+func confirmMessage(question, message string, gen *generator) (string, bool) {
+	for {
+		switch askForConfirmation(question) {
+		case choiceCommit:
+			return message, true
+		case choiceEdit:
+			edited, err := git.EditMessage(message)
+			if err != nil {
+				logger.Error("Error editing commit message: %v", err)
+				continue
+			}
+			if edited == "" {
+				logger.Error("Empty commit message")
+				return "", false
+			}
+			message = edited
+			logger.Println("\n📝 Edited Commit Message:")
+			logger.Printf("%s\n\n", message)
+		case choiceRegenerate:
+			regenerated, err := gen.regenerate()
+			if err != nil {
+				logger.Error("Error generating commit message: %v", err)
+				continue
+			}
+			message = regenerated
+			logger.Println("\n📝 Generated Commit Message:")
+			logger.Printf("%s\n\n", message)
+		case choiceRefine:
+			logger.Printf("Feedback: ")
+			feedback, _ := stdin.ReadString('\n')
+			feedback = strings.TrimSpace(feedback)
+			if feedback == "" {
+				continue
+			}
+			refined, err := gen.refine(message, feedback)
+			if err != nil {
+				logger.Error("Error refining commit message: %v", err)
+				continue
+			}
+			message = refined
+			logger.Println("\n📝 Refined Commit Message:")
+			logger.Printf("%s\n\n", message)
+		default:
+			return "", false
+		}
+	}
+}
+
+func askForConfirmation(question string) int {
+	logger.Printf("%s [y]es, [e]dit, [r]egenerate, re[f]ine, [N]o ", question)
+	text, _ := stdin.ReadString('\n')
+	switch strings.TrimSpace(strings.ToLower(text)) {
+	case "y", "yes":
+		return choiceCommit
+	case "e", "edit":
+		return choiceEdit
+	case "r", "regenerate":
+		return choiceRegenerate
+	case "f", "refine":
+		return choiceRefine
+	default:
+		return choiceCancel
+	}
+}
+
+// selectCandidate lists the generated messages and lets the user pick one by number
+func selectCandidate(messages []string) (string, bool) {
+	logger.Println("\n📝 Generated Commit Messages:")
+	for i, message := range messages {
+		logger.Printf("\n[%d] %s\n", i+1, strings.ReplaceAll(message, "\n", "\n    "))
+	}
+	logger.Println()
+
+	for {
+		logger.Printf("Select a message [1-%d] or [N]o ", len(messages))
+		text, _ := stdin.ReadString('\n')
+		text = strings.TrimSpace(text)
+		n, err := strconv.Atoi(text)
+		switch {
+		case err == nil && n >= 1 && n <= len(messages):
+			return messages[n-1], true
+		case err == nil:
+			logger.Error("Please enter a number between 1 and %d", len(messages))
+		default:
+			return "", false
+		}
+	}
 }
 
 func init() {
-	cobra.OnInitialize(initConfig)
 	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $XDG_CONFIG_HOME/kommit/config.yaml or $HOME/.config/kommit/config.yaml)")
+	rootCmd.PersistentFlags().String("preset", "", "Rules preset for the commit message style: "+strings.Join(config.PresetNames(), ", "))
+	if err := viper.BindPFlag("preset", rootCmd.PersistentFlags().Lookup("preset")); err != nil {
+		logger.Fatal("Failed to bind flag: %v", err)
+	}
 	rootCmd.Flags().BoolVarP(&yolo, "yolo", "y", false, "Automatically stage all changes, commit, and push without confirmation")
+	rootCmd.Flags().IntVarP(&candidates, "candidates", "n", 1, "Generate several commit messages and pick one")
+	rootCmd.Flags().BoolVar(&amend, "amend", false, "Generate a new message for the last commit (including staged changes) and amend it")
+	rootCmd.MarkFlagsMutuallyExclusive("yolo", "amend")
+	rootCmd.Flags().String("on-secrets", "", "Action when secrets are detected in the staged changes: warn or abort")
+	if err := viper.BindPFlag("redact.on_detect", rootCmd.Flags().Lookup("on-secrets")); err != nil {
+		logger.Fatal("Failed to bind flag: %v", err)
+	}
 }
 
 // initConfig initializes the configuration
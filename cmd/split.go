@@ -0,0 +1,143 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"strings"
+
+	"github.com/madflow/kommit/internal/config"
+	"github.com/madflow/kommit/internal/git"
+	"github.com/madflow/kommit/internal/llm"
+	"github.com/madflow/kommit/internal/logger"
+	"github.com/madflow/kommit/internal/prompt"
+	"github.com/madflow/kommit/internal/split"
+	"github.com/spf13/cobra"
+)
+
+// splitCmd commits the staged changes as several logical commits
+var splitCmd = &cobra.Command{
+	Use:   "split",
+	Short: "Split the staged changes into several logical commits",
+	Long: `Split the staged changes into several logical commits.
+
+The model groups the staged files into coherent commits and a message is
+generated for every group. Each message has to be accepted before anything
+is committed. The groups are then committed one after another by staging only
+their files. Files are never split, all changes of a file end up in the same
+commit.
+
+The staged changes are saved before and restored afterwards, so if a commit
+fails the changes that were not committed stay staged.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if !git.IsGitRepo() {
+			logger.Fatal("Not in a git repository")
+		}
+
+		hasChanges, err := git.HasStagedChanges()
+		if err != nil {
+			logger.Fatal("Error checking for changes: %v", err)
+		}
+		if !hasChanges {
+			logger.Success("No changes to commit")
+			return
+		}
+		if _, err := git.GetHead(); err != nil {
+			logger.Fatal("Cannot split the first commit of a repository")
+		}
+
+		repoCtx, err := git.GetRepoContext()
+		if err != nil {
+			logger.Fatal("Error getting repository context: %v", err)
+		}
+		displayRepoContext(repoCtx)
+		if len(repoCtx.FileChanges) < 2 {
+			logger.Success("Only one file is staged, nothing to split")
+			return
+		}
+
+		gitDiff, err := git.GetGitDiff()
+		if err != nil {
+			logger.Fatal("Error getting git diff: %v", err)
+		}
+
+		cfg := config.Get()
+		provider, err := llm.New(cfg)
+		if err != nil {
+			logger.Fatal("Error creating provider: %v", err)
+		}
+
+		logger.Info("Grouping changes...")
+		groups, err := planSplit(cmd.Context(), cfg, provider, gitDiff, repoCtx)
+		checkGenerateError(err)
+
+		logger.Println("\n🧩 Proposed Commits:")
+		for i, group := range groups {
+			files := make([]string, 0, len(group.Changes))
+			for _, change := range group.Changes {
+				files = append(files, change.FilePath)
+			}
+			logger.Printf("[%d] %s\n    %s\n", i+1, group.Title, strings.Join(files, ", "))
+		}
+
+		streamOutput(cfg, provider)
+
+		examples := styleExamples(cfg, "HEAD")
+		messages := make([]string, 0, len(groups))
+		for i, group := range groups {
+			logger.Println("================================")
+			logger.Printf("🔖 Commit %d of %d: %s\n\n", i+1, len(groups), group.Title)
+
+			groupDiff, err := git.GetPathsDiff(group.Paths(), "--cached")
+			if err != nil {
+				logger.Fatal("Error getting git diff: %v", err)
+			}
+
+			req := &llm.Request{RepoCtx: group.Context(repoCtx), Examples: examples}
+			checkGenerateError(prepareRequest(cmd.Context(), cfg, provider, groupDiff, req))
+			generated, err := generateCandidates(cmd.Context(), cfg, provider, req, 1)
+			checkGenerateError(err)
+
+			logger.Println("\n📝 Generated Commit Message:")
+			logger.Printf("%s\n\n", generated[0])
+
+			gen := &generator{
+				ctx:      cmd.Context(),
+				cfg:      cfg,
+				provider: provider,
+				req:      req,
+				attempt:  1,
+			}
+			message, confirmed := confirmMessage("Do you want to use this message?", generated[0], gen)
+			if !confirmed {
+				logger.Error("Split cancelled by user")
+				return
+			}
+			messages = append(messages, message)
+		}
+
+		committed, err := split.Commit(groups, messages)
+		if err != nil {
+			logger.Fatal("Error after %d of %d commits, the remaining changes are still staged: %v", committed, len(groups), err)
+		}
+		logger.Success("%d commits created successfully!", committed)
+	},
+}
+
+// planSplit asks the model to group the staged changes, the request can be cancelled with Ctrl-C
+func planSplit(ctx context.Context, cfg *config.Config, provider llm.Provider, gitDiff string, repoCtx *git.RepoContext) ([]split.Group, error) {
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt)
+	defer stop()
+
+	gitDiff, _, err := sanitizeDiff(cfg, gitDiff)
+	if err != nil {
+		return nil, err
+	}
+	budget := prompt.SplitBudget(cfg.Diff.ContextSize, cfg.Diff.ResponseTokens, repoCtx)
+	return split.Plan(ctx, provider, gitDiff, repoCtx, budget)
+}
+
+func init() {
+	rootCmd.AddCommand(splitCmd)
+}
@@ -0,0 +1,136 @@
+package cmd
+
+import (
+	"github.com/madflow/kommit/internal/config"
+	"github.com/madflow/kommit/internal/git"
+	"github.com/madflow/kommit/internal/llm"
+	"github.com/madflow/kommit/internal/logger"
+	"github.com/spf13/cobra"
+)
+
+var squashCommit bool
+
+// squashCmd generates a single message for all commits of the current branch
+var squashCmd = &cobra.Command{
+	Use:   "squash [base]",
+	Short: "Generate a single message for all commits of the current branch",
+	Long: `Generate a single commit message for all commits of the current branch.
+
+The commits between the merge-base with the base branch and HEAD are collected
+and their messages are sent to the model together with the combined diff. The
+base branch defaults to the default branch of origin, or main or master.
+
+With --commit the commits are squashed into one with the generated message
+(git reset --soft to the merge-base followed by git commit).`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if !git.IsGitRepo() {
+			logger.Fatal("Not in a git repository")
+		}
+
+		base, mergeBase, commits := branchCommits(args)
+		if len(commits) == 0 {
+			logger.Success("No commits to squash")
+			return
+		}
+
+		// Staged changes would end up in the squashed commit
+		if squashCommit {
+			hasChanges, err := git.HasStagedChanges()
+			if err != nil {
+				logger.Fatal("Error checking for changes: %v", err)
+			}
+			if hasChanges {
+				logger.Fatal("Please commit or unstage your staged changes before squashing")
+			}
+		}
+
+		logger.Printf("📚 Squashing %d commits since %s:\n", len(commits), base)
+		messages := make([]string, 0, len(commits))
+		for _, commit := range commits {
+			logger.Printf("%s %s\n", shortHash(commit.Hash), commit.Subject())
+			messages = append(messages, commit.Message)
+		}
+		logger.Println()
+
+		repoCtx, err := git.GetRepoContextForDiff(mergeBase, "HEAD")
+		if err != nil {
+			logger.Fatal("Error getting repository context: %v", err)
+		}
+		gitDiff, err := git.GetDiff(mergeBase, "HEAD")
+		if err != nil {
+			logger.Fatal("Error getting git diff: %v", err)
+		}
+
+		cfg := config.Get()
+		provider, err := llm.New(cfg)
+		if err != nil {
+			logger.Fatal("Error creating provider: %v", err)
+		}
+		streamOutput(cfg, provider)
+
+		req := &llm.Request{
+			RepoCtx:  repoCtx,
+			Examples: styleExamples(cfg, mergeBase),
+			Commits:  messages,
+		}
+		checkGenerateError(prepareRequest(cmd.Context(), cfg, provider, gitDiff, req))
+		generated, err := generateCandidates(cmd.Context(), cfg, provider, req, 1)
+		checkGenerateError(err)
+
+		logger.Println("\n📝 Generated Commit Message:")
+		logger.Printf("%s\n\n", generated[0])
+
+		if !squashCommit {
+			return
+		}
+
+		gen := &generator{
+			ctx:      cmd.Context(),
+			cfg:      cfg,
+			provider: provider,
+			req:      req,
+			attempt:  1,
+		}
+		message, confirmed := confirmMessage("Do you want to squash the commits with this message?", generated[0], gen)
+		if !confirmed {
+			logger.Error("Squash cancelled by user")
+			return
+		}
+
+		if err := git.SquashCommits(mergeBase, message); err != nil {
+			logger.Fatal("Error squashing commits: %v", err)
+		}
+		logger.Success("%d commits squashed successfully!", len(commits))
+	},
+}
+
+// branchCommits returns the base branch given in the arguments (or the default
+// branch), the merge-base of HEAD with it and the commits since, excluding merges
+func branchCommits(args []string) (string, string, []git.Commit) {
+	var base string
+	if len(args) > 0 {
+		base = args[0]
+	} else {
+		var err error
+		base, err = git.GetDefaultBranch()
+		if err != nil {
+			logger.Fatal("%v, please specify the base branch", err)
+		}
+	}
+
+	mergeBase, err := git.GetMergeBase(base, "HEAD")
+	if err != nil {
+		logger.Fatal("Error finding merge-base: %v", err)
+	}
+	commits, err := git.GetCommits("--no-merges", mergeBase+"..HEAD")
+	if err != nil {
+		logger.Fatal("Error reading commits: %v", err)
+	}
+	return base, mergeBase, commits
+}
+
+func init() {
+	squashCmd.Flags().BoolVar(&squashCommit, "commit", false, "Squash the commits into one with the generated message")
+	rootCmd.AddCommand(squashCmd)
+}
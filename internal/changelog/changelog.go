@@ -0,0 +1,222 @@
+package changelog
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/madflow/kommit/internal/conventional"
+	"github.com/madflow/kommit/internal/git"
+	"github.com/madflow/kommit/internal/llm"
+	"github.com/madflow/kommit/internal/prompt"
+)
+
+// Sections of a release in the Keep a Changelog format
+const (
+	SectionAdded      = "Added"
+	SectionChanged    = "Changed"
+	SectionDeprecated = "Deprecated"
+	SectionRemoved    = "Removed"
+	SectionFixed      = "Fixed"
+	SectionSecurity   = "Security"
+)
+
+// Sections lists the sections in the order they are rendered
+var Sections = []string{SectionAdded, SectionChanged, SectionDeprecated, SectionRemoved, SectionFixed, SectionSecurity}
+
+// typeSections maps conventional commit types to sections. Types missing here,
+// e.g. docs, test or chore, are left out of the changelog.
+var typeSections = map[string]string{
+	"feat":      SectionAdded,
+	"fix":       SectionFixed,
+	"perf":      SectionChanged,
+	"revert":    SectionChanged,
+	"deprecate": SectionDeprecated,
+	"remove":    SectionRemoved,
+	"security":  SectionSecurity,
+}
+
+// Header is the introduction of a new changelog file
+const Header = `# Changelog
+
+All notable changes to this project will be documented in this file.
+
+The format is based on [Keep a Changelog](https://keepachangelog.com/en/1.1.0/).
+`
+
+// Entry is a single change listed in a section of the changelog
+type Entry struct {
+	Section string
+	Text    string
+}
+
+// parseHeader parses the subject of a conventional commit. It reports false if the
+// subject does not follow the format or the type is neither a type of the
+// changelog nor one of the given types, e.g. the subsystem in "net: fix race".
+func parseHeader(commit git.Commit, types []string) (conventional.Header, bool) {
+	header, ok := conventional.ParseHeader(commit.Subject())
+	if !ok {
+		return conventional.Header{}, false
+	}
+	if _, known := typeSections[header.Type]; !known && !slices.Contains(types, header.Type) {
+		return conventional.Header{}, false
+	}
+	return header, true
+}
+
+// Section returns the changelog section of a conventional commit with one of the
+// given types. An empty section means the commit is left out. It reports false
+// if the commit does not follow the conventional format.
+func Section(commit git.Commit, types []string) (string, bool) {
+	header, ok := parseHeader(commit, types)
+	if !ok {
+		return "", false
+	}
+	section := typeSections[header.Type]
+	if section == "" && conventional.IsBreaking(commit.Message) {
+		section = SectionChanged
+	}
+	return section, true
+}
+
+// NewEntry returns the entry of the commit in the given section. The type is
+// removed from conventional commits with one of the given types.
+func NewEntry(commit git.Commit, section string, types []string) Entry {
+	text := commit.Subject()
+	if header, ok := parseHeader(commit, types); ok {
+		text = header.Description
+		if header.Scope != "" {
+			text = fmt.Sprintf("**%s:** %s", header.Scope, text)
+		}
+	}
+	if conventional.IsBreaking(commit.Message) {
+		text = "**BREAKING:** " + text
+	}
+	return Entry{Section: section, Text: text}
+}
+
+// Entries returns the changelog entries of the commits in the given order.
+// Conventional commits are sorted into sections by their type, the model sorts
+// the remaining commits. Types other than the ones of the changelog, e.g. docs or
+// chore, are only recognized if they are in types.
+func Entries(ctx context.Context, provider llm.Provider, commits []git.Commit, types []string) ([]Entry, error) {
+	sections := make([]string, len(commits))
+	var unknown []git.Commit
+	var unknownIndex []int
+	for i, commit := range commits {
+		section, ok := Section(commit, types)
+		if !ok {
+			unknown = append(unknown, commit)
+			unknownIndex = append(unknownIndex, i)
+			continue
+		}
+		sections[i] = section
+	}
+
+	if len(unknown) > 0 {
+		subjects := make([]string, len(unknown))
+		for i, commit := range unknown {
+			subjects[i] = commit.Subject()
+		}
+		answer, err := provider.Complete(ctx, prompt.ChangelogSystem, prompt.ChangelogUser(subjects))
+		if err != nil {
+			return nil, fmt.Errorf("failed to sort commits: %w", err)
+		}
+		for i, section := range ParseSections(answer, len(unknown)) {
+			sections[unknownIndex[i]] = section
+		}
+	}
+
+	var entries []Entry
+	for i, commit := range commits {
+		if sections[i] != "" {
+			entries = append(entries, NewEntry(commit, sections[i], types))
+		}
+	}
+	return entries, nil
+}
+
+// sectionLinePattern matches a line of the answer of the model like "3: Fixed"
+var sectionLinePattern = regexp.MustCompile(`^\s*(\d+)\s*[:.)-]\s*\**([A-Za-z]+)`)
+
+// ParseSections reads the sections the model assigned to n numbered commits.
+// Commits the model skipped get an empty section, commits without a valid
+// answer are listed as changed.
+func ParseSections(answer string, n int) []string {
+	sections := make([]string, n)
+	for i := range sections {
+		sections[i] = SectionChanged
+	}
+
+	for _, line := range strings.Split(answer, "\n") {
+		match := sectionLinePattern.FindStringSubmatch(line)
+		if match == nil {
+			continue
+		}
+		number, err := strconv.Atoi(match[1])
+		if err != nil || number < 1 || number > n {
+			continue
+		}
+		if strings.EqualFold(match[2], "skip") {
+			sections[number-1] = ""
+			continue
+		}
+		for _, section := range Sections {
+			if strings.EqualFold(match[2], section) {
+				sections[number-1] = section
+			}
+		}
+	}
+	return sections
+}
+
+// Release is a version with its changes
+type Release struct {
+	// Version is the released version or "Unreleased"
+	Version string
+	// Date is the release date in the format YYYY-MM-DD, it is omitted if empty
+	Date    string
+	Entries []Entry
+}
+
+// Markdown renders the release in the Keep a Changelog format
+func (r Release) Markdown() string {
+	var sb strings.Builder
+	sb.WriteString("## [" + r.Version + "]")
+	if r.Date != "" {
+		sb.WriteString(" - " + r.Date)
+	}
+	sb.WriteString("\n")
+
+	for _, section := range Sections {
+		var items []string
+		for _, entry := range r.Entries {
+			if entry.Section == section {
+				items = append(items, "- "+entry.Text)
+			}
+		}
+		if len(items) > 0 {
+			fmt.Fprintf(&sb, "\n### %s\n\n%s\n", section, strings.Join(items, "\n"))
+		}
+	}
+	return sb.String()
+}
+
+// Insert adds the rendered release to the changelog above the latest release.
+// An empty changelog gets the default header.
+func Insert(changelog, release string) string {
+	if strings.TrimSpace(changelog) == "" {
+		return Header + "\n" + release
+	}
+
+	if strings.HasPrefix(changelog, "## ") {
+		return release + "\n" + changelog
+	}
+	if i := strings.Index(changelog, "\n## "); i != -1 {
+		return changelog[:i+1] + release + "\n" + changelog[i+1:]
+	}
+	return strings.TrimRight(changelog, "\n") + "\n\n" + release
+}
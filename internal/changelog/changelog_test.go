@@ -0,0 +1,203 @@
+package changelog
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/madflow/kommit/internal/git"
+	"github.com/madflow/kommit/internal/llm"
+)
+
+// fakeProvider answers every completion with a fixed answer and records the prompt
+type fakeProvider struct {
+	answer string
+	user   string
+}
+
+func (p *fakeProvider) GenerateCommitMessage(ctx context.Context, req *llm.Request) (string, error) {
+	return "", nil
+}
+
+func (p *fakeProvider) Complete(ctx context.Context, system, user string) (string, error) {
+	p.user = user
+	return p.answer, nil
+}
+
+// TestEntries tests sorting conventional commits by type and the others with the model
+func TestEntries(t *testing.T) {
+	commits := []git.Commit{
+		{Message: "feat(cli): add changelog command"},
+		{Message: "Fix crash on empty diff"},
+		{Message: "chore: update dependencies"},
+		{Message: "refactor!: rename config keys"},
+		{Message: "Reformat code"},
+		{Message: "fix: handle renames\n\nBREAKING CHANGE: FileChange has a new field"},
+		{Message: "net: fix race in socket release"},
+		{Message: "README: document presets"},
+	}
+	provider := &fakeProvider{answer: "1: Fixed\n2: Skip\n3: Fixed\n4: Skip"}
+
+	entries, err := Entries(context.Background(), provider, commits, []string{"feat", "fix", "chore", "refactor"})
+	if err != nil {
+		t.Fatalf("Entries() unexpected error: %v", err)
+	}
+
+	expected := []Entry{
+		{Section: SectionAdded, Text: "**cli:** add changelog command"},
+		{Section: SectionFixed, Text: "Fix crash on empty diff"},
+		{Section: SectionChanged, Text: "**BREAKING:** rename config keys"},
+		{Section: SectionFixed, Text: "**BREAKING:** handle renames"},
+		{Section: SectionFixed, Text: "net: fix race in socket release"},
+	}
+	if !reflect.DeepEqual(entries, expected) {
+		t.Errorf("Entries() = %+v, want %+v", entries, expected)
+	}
+
+	expectedUser := "Commits:\n1. Fix crash on empty diff\n2. Reformat code\n3. net: fix race in socket release\n4. README: document presets"
+	if provider.user != expectedUser {
+		t.Errorf("Entries() sent %q, want %q", provider.user, expectedUser)
+	}
+}
+
+// TestSection tests recognizing conventional commits by their type
+func TestSection(t *testing.T) {
+	types := []string{"feat", "fix", "docs", "chore"}
+	tests := []struct {
+		name     string
+		message  string
+		expected string
+		ok       bool
+	}{
+		{name: "type of the changelog", message: "feat(cli): add lint command", expected: SectionAdded, ok: true},
+		{name: "type of the changelog missing in types", message: "security: escape paths", expected: SectionSecurity, ok: true},
+		{name: "type left out", message: "docs: describe presets", ok: true},
+		{name: "breaking type left out", message: "chore!: drop Go 1.21", expected: SectionChanged, ok: true},
+		{name: "package prefix", message: "cmd: add lint command"},
+		{name: "kernel subsystem", message: "net: fix race in socket release"},
+		{name: "file prefix", message: "README: document presets"},
+		{name: "plain subject", message: "Add lint command"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			section, ok := Section(git.Commit{Message: tt.message}, types)
+			if section != tt.expected || ok != tt.ok {
+				t.Errorf("Section() = %q, %v, want %q, %v", section, ok, tt.expected, tt.ok)
+			}
+		})
+	}
+}
+
+// TestParseSections tests reading the sections assigned by the model
+func TestParseSections(t *testing.T) {
+	tests := []struct {
+		name     string
+		answer   string
+		n        int
+		expected []string
+	}{
+		{
+			name:     "all assigned",
+			answer:   "1: Added\n2: fixed\n3: Skip",
+			n:        3,
+			expected: []string{SectionAdded, SectionFixed, ""},
+		},
+		{
+			name:     "other separators and noise",
+			answer:   "Here you go:\n1. **Security**\n2) Removed\n7: Added",
+			n:        2,
+			expected: []string{SectionSecurity, SectionRemoved},
+		},
+		{
+			name:     "missing and invalid answers",
+			answer:   "2: Improved",
+			n:        2,
+			expected: []string{SectionChanged, SectionChanged},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := ParseSections(tt.answer, tt.n); !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("ParseSections() = %q, want %q", result, tt.expected)
+			}
+		})
+	}
+}
+
+// TestReleaseMarkdown tests rendering a release in the Keep a Changelog format
+func TestReleaseMarkdown(t *testing.T) {
+	tests := []struct {
+		name     string
+		release  Release
+		expected string
+	}{
+		{
+			name: "version with date",
+			release: Release{
+				Version: "1.2.0",
+				Date:    "2026-10-17",
+				Entries: []Entry{
+					{Section: SectionFixed, Text: "Fix crash"},
+					{Section: SectionAdded, Text: "Add changelog"},
+					{Section: SectionAdded, Text: "Add lint"},
+				},
+			},
+			expected: "## [1.2.0] - 2026-10-17\n\n### Added\n\n- Add changelog\n- Add lint\n\n### Fixed\n\n- Fix crash\n",
+		},
+		{
+			name:     "unreleased without entries",
+			release:  Release{Version: "Unreleased"},
+			expected: "## [Unreleased]\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := tt.release.Markdown(); result != tt.expected {
+				t.Errorf("Markdown() = %q, want %q", result, tt.expected)
+			}
+		})
+	}
+}
+
+// TestInsert tests adding a release to an existing changelog
+func TestInsert(t *testing.T) {
+	release := "## [1.1.0]\n\n### Added\n\n- New\n"
+
+	tests := []struct {
+		name      string
+		changelog string
+		expected  string
+	}{
+		{
+			name:      "new changelog",
+			changelog: "",
+			expected:  Header + "\n" + release,
+		},
+		{
+			name:      "above the latest release",
+			changelog: "# Changelog\n\nIntro\n\n## [1.0.0]\n\n### Added\n\n- Old\n",
+			expected:  "# Changelog\n\nIntro\n\n" + release + "\n## [1.0.0]\n\n### Added\n\n- Old\n",
+		},
+		{
+			name:      "no releases yet",
+			changelog: "# Changelog\n\nIntro\n",
+			expected:  "# Changelog\n\nIntro\n\n" + release,
+		},
+		{
+			name:      "release at the top",
+			changelog: "## [1.0.0]\n",
+			expected:  release + "\n## [1.0.0]\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := Insert(tt.changelog, release); result != tt.expected {
+				t.Errorf("Insert() = %q, want %q", result, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,71 @@
+package cleanup
+
+import (
+	"regexp"
+	"strings"
+)
+
+// preamblePattern matches lines models put in front of the commit message, e.g.
+// "Here is your commit message:" or "Commit message:"
+var preamblePattern = regexp.MustCompile(`(?i)^(?:(?:sure|certainly|okay|ok)[!,.].*:|here(?: is|'s)\b.*:|(?:suggested |generated |proposed )?commit message:?)\s*$`)
+
+// labelPattern matches a label in front of the subject, e.g. "Commit message: Add feature"
+var labelPattern = regexp.MustCompile(`(?i)^(?:suggested |generated |proposed )?commit message:\s*`)
+
+// headingPattern matches a markdown heading marker in front of the subject, but
+// not ticket references like "#123 Fix login"
+var headingPattern = regexp.MustCompile(`^#+\s+`)
+
+// closingPattern matches remarks models add after the commit message
+var closingPattern = regexp.MustCompile(`(?i)^(?:let me know|i hope|feel free|this commit message)\b`)
+
+// Message removes the wrappers models tend to put around a commit message: code
+// fences around the whole message, preambles like "Here is your commit message:",
+// closing remarks, quotes and markdown emphasis around the subject
+func Message(message string) string {
+	lines := trimRemarks(strings.Split(strings.TrimSpace(message), "\n"))
+	if unfenced, ok := unfence(lines); ok {
+		lines = trimRemarks(unfenced)
+	}
+	if len(lines) == 0 {
+		return ""
+	}
+
+	subject := labelPattern.ReplaceAllString(strings.TrimSpace(lines[0]), "")
+	subject = headingPattern.ReplaceAllString(subject, "")
+	subject = trimPair(subject, "**")
+	if len(lines) == 1 {
+		subject = trimPair(trimPair(trimPair(subject, `"`), "'"), "`")
+	}
+	lines[0] = subject
+
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+// trimRemarks removes preambles, closing remarks and blank lines around the message
+func trimRemarks(lines []string) []string {
+	for len(lines) > 0 && (preamblePattern.MatchString(strings.TrimSpace(lines[0])) || strings.TrimSpace(lines[0]) == "") {
+		lines = lines[1:]
+	}
+	for len(lines) > 0 && (closingPattern.MatchString(strings.TrimSpace(lines[len(lines)-1])) || strings.TrimSpace(lines[len(lines)-1]) == "") {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// unfence returns the content of the code block if the lines start and end with a
+// fence. Code blocks inside the message, e.g. in the body, are kept.
+func unfence(lines []string) ([]string, bool) {
+	if len(lines) < 2 || !strings.HasPrefix(strings.TrimSpace(lines[0]), "```") || strings.TrimSpace(lines[len(lines)-1]) != "```" {
+		return lines, false
+	}
+	return lines[1 : len(lines)-1], true
+}
+
+// trimPair removes the delimiter from both ends of the text if it is on both ends
+func trimPair(text, delimiter string) string {
+	if len(text) > 2*len(delimiter) && strings.HasPrefix(text, delimiter) && strings.HasSuffix(text, delimiter) {
+		return strings.TrimSpace(text[len(delimiter) : len(text)-len(delimiter)])
+	}
+	return text
+}
@@ -0,0 +1,106 @@
+package cleanup
+
+import "testing"
+
+// TestMessage tests removing the wrappers around generated commit messages
+func TestMessage(t *testing.T) {
+	tests := []struct {
+		name     string
+		message  string
+		expected string
+	}{
+		{
+			name:     "clean message",
+			message:  "Add lint command\n\nChecks messages against the rules.\n",
+			expected: "Add lint command\n\nChecks messages against the rules.",
+		},
+		{
+			name:     "code fence",
+			message:  "```\nAdd lint command\n\nChecks messages.\n```",
+			expected: "Add lint command\n\nChecks messages.",
+		},
+		{
+			name:     "preamble and fence with language",
+			message:  "Here is your commit message:\n\n```text\nAdd lint command\n```\n\nLet me know if you need changes.",
+			expected: "Add lint command",
+		},
+		{
+			name:     "preamble and closing remark",
+			message:  "Sure! Here's a commit message for your changes:\n\nAdd lint command\n\nChecks messages.\n\nI hope this helps!",
+			expected: "Add lint command\n\nChecks messages.",
+		},
+		{
+			name:     "fenced snippet in the body",
+			message:  "Add foo helper\n\nUse it like this:\n```\nfoo()\n```",
+			expected: "Add foo helper\n\nUse it like this:\n```\nfoo()\n```",
+		},
+		{
+			name:     "fenced message with a snippet in the body",
+			message:  "```\nAdd foo helper\n\nUse it like this:\n    foo()\n```",
+			expected: "Add foo helper\n\nUse it like this:\n    foo()",
+		},
+		{
+			name:     "label in front of the subject",
+			message:  "Commit message: Add lint command",
+			expected: "Add lint command",
+		},
+		{
+			name:     "label on its own line",
+			message:  "Suggested commit message:\nfeat: add lint command",
+			expected: "feat: add lint command",
+		},
+		{
+			name:     "ticket reference",
+			message:  "#123 Fix login redirect",
+			expected: "#123 Fix login redirect",
+		},
+		{
+			name:     "ticket reference with colon",
+			message:  "#123: fix login",
+			expected: "#123: fix login",
+		},
+		{
+			name:     "quoted subject",
+			message:  `"Add lint command"`,
+			expected: "Add lint command",
+		},
+		{
+			name:     "markdown emphasis and heading",
+			message:  "# **Add lint command**\n\nChecks messages.",
+			expected: "Add lint command\n\nChecks messages.",
+		},
+		{
+			name:     "quotes in the body are kept",
+			message:  "\"Add\" lint command\n\nUse \"kommit lint\".",
+			expected: "\"Add\" lint command\n\nUse \"kommit lint\".",
+		},
+		{
+			name:     "subject starting with OK",
+			message:  "OK button stays disabled on empty form\n\nCheck the input first.",
+			expected: "OK button stays disabled on empty form\n\nCheck the input first.",
+		},
+		{
+			name:     "subject starting with Okay",
+			message:  "Okay dialog closes on escape",
+			expected: "Okay dialog closes on escape",
+		},
+		{
+			name:     "okay preamble",
+			message:  "Okay, here is the message:\nAdd lint command",
+			expected: "Add lint command",
+		},
+		{
+			name:     "only a preamble",
+			message:  "Here is the commit message:",
+			expected: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := Message(tt.message); result != tt.expected {
+				t.Errorf("Message() = %q, want %q", result, tt.expected)
+			}
+		})
+	}
+}
@@ -4,29 +4,202 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"time"
 
+	"github.com/madflow/kommit/internal/diff"
 	"github.com/madflow/kommit/internal/git"
+	"github.com/madflow/kommit/internal/redact"
 	"github.com/spf13/viper"
 )
 
 // Config holds the application configuration
 type Config struct {
-	Ollama OllamaConfig `mapstructure:"ollama"`
-	Rules  string       `mapstructure:"rules"`
+	Provider   string           `mapstructure:"provider"`
+	Ollama     OllamaConfig     `mapstructure:"ollama"`
+	OpenAI     OpenAIConfig     `mapstructure:"openai"`
+	Diff       DiffConfig       `mapstructure:"diff"`
+	Summarize  SummarizeConfig  `mapstructure:"summarize"`
+	Redact     RedactConfig     `mapstructure:"redact"`
+	PR         PRConfig         `mapstructure:"pr"`
+	Lint       LintConfig       `mapstructure:"lint"`
+	Validate   ValidateConfig   `mapstructure:"validate"`
+	Structured StructuredConfig `mapstructure:"structured"`
+	Prompt     PromptConfig     `mapstructure:"prompt"`
+	Examples   ExamplesConfig   `mapstructure:"examples"`
+	// Preset selects built-in rules and lint settings for a commit message style
+	Preset string `mapstructure:"preset"`
+	Rules  string `mapstructure:"rules"`
+	// ExtraRules are appended to the rules, e.g. to extend the rules of a preset
+	ExtraRules string `mapstructure:"extra_rules"`
 }
 
 // OllamaConfig holds configuration for the Ollama API
 type OllamaConfig struct {
 	ServerURL string `mapstructure:"server_url"`
 	Model     string `mapstructure:"model"`
+	API       string `mapstructure:"api"`
+	Stream    bool   `mapstructure:"stream"`
+	// Timeout limits the wait for the response of a single request attempt, streamed
+	// answers are not limited once the first token arrived (0 disables the timeout)
+	Timeout time.Duration `mapstructure:"timeout"`
+	// Retries is the number of retries for transient failures
+	Retries int `mapstructure:"retries"`
+	// RetryBackoff is the delay before the first retry, doubled for every further retry
+	RetryBackoff time.Duration `mapstructure:"retry_backoff"`
+}
+
+// OpenAIConfig holds configuration for OpenAI compatible chat completions APIs
+type OpenAIConfig struct {
+	ServerURL string `mapstructure:"server_url"`
+	Model     string `mapstructure:"model"`
+	APIKey    string `mapstructure:"api_key"`
+}
+
+// DiffConfig holds configuration for preparing the diff sent to the model
+type DiffConfig struct {
+	// ContextSize is the context window of the model in tokens
+	ContextSize int `mapstructure:"context_size"`
+	// ResponseTokens is the part of the context window reserved for the response
+	ResponseTokens int `mapstructure:"response_tokens"`
+	// Include limits the diff sent to the model to files matching these glob patterns
+	Include []string `mapstructure:"include"`
+	// Exclude hides the content of files matching these glob patterns from the model
+	Exclude []string `mapstructure:"exclude"`
+}
+
+// SummarizeConfig holds configuration for summarizing large diffs per file before
+// generating the commit message
+type SummarizeConfig struct {
+	// Mode is one of "never", "auto" (when the diff exceeds the context window) or "always"
+	Mode string `mapstructure:"mode"`
+	// Concurrency is the maximum number of summary requests running in parallel
+	Concurrency int `mapstructure:"concurrency"`
+}
+
+// RedactConfig holds configuration for masking secrets in the diff before it is sent to the model
+type RedactConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// Patterns are additional regular expressions of secrets to mask
+	Patterns []string `mapstructure:"patterns"`
+	// OnDetect is the action when secrets are detected: "warn" or "abort"
+	OnDetect string `mapstructure:"on_detect"`
+}
+
+// PRConfig holds configuration for generating pull request descriptions
+type PRConfig struct {
+	// Rules guide the model in writing the title and description of a pull request
+	Rules string `mapstructure:"rules"`
+}
+
+// LintConfig holds the checks of commit messages
+type LintConfig struct {
+	// MaxSubjectLength is the maximum number of characters of the subject line (0 disables the check)
+	MaxSubjectLength int `mapstructure:"max_subject_length"`
+	// BlankLine requires a blank line between the subject and the body
+	BlankLine bool `mapstructure:"blank_line"`
+	// Imperative rejects subjects starting with common verbs in past tense, third person or gerund
+	Imperative bool `mapstructure:"imperative"`
+	// NoTrailingPeriod rejects subjects ending with a period
+	NoTrailingPeriod bool `mapstructure:"no_trailing_period"`
+	// Conventional requires subjects in the conventional commits format type(scope): description
+	Conventional bool `mapstructure:"conventional"`
+	// Types are the allowed conventional commit types (empty allows all)
+	Types []string `mapstructure:"types"`
+	// Scopes are the allowed conventional commit scopes (empty allows all)
+	Scopes []string `mapstructure:"scopes"`
+}
+
+// ValidateConfig holds configuration for checking generated commit messages
+// against the lint rules before they are committed
+type ValidateConfig struct {
+	// Retries is the number of times a message violating the lint rules is sent
+	// back to the model with the problems (0 only removes wrappers like code fences)
+	Retries int `mapstructure:"retries"`
+}
+
+// StructuredConfig holds configuration for generating commit messages as JSON
+// objects with type, scope, subject, body, breaking and footers
+type StructuredConfig struct {
+	// Enabled constrains the answer of the model to the JSON schema of a commit message
+	Enabled bool `mapstructure:"enabled"`
+	// Template is the Go template rendering the fields into the commit message
+	// (empty uses the conventional commits format)
+	Template string `mapstructure:"template"`
+}
+
+// PromptConfig holds Go templates replacing the built-in prompts of commit messages
+type PromptConfig struct {
+	// System is the template of the system prompt (empty uses the built-in prompt with the rules)
+	System string `mapstructure:"system"`
+	// User is the template of the user prompt (empty uses the built-in prompt with the context and the diff)
+	User string `mapstructure:"user"`
+	// RecentCommits is the number of commit subjects available to the templates
+	RecentCommits int `mapstructure:"recent_commits"`
+}
+
+// ExamplesConfig holds configuration for showing recent commit messages of the
+// repository to the model as examples of its style
+type ExamplesConfig struct {
+	// Count is the number of commit messages to show (0 disables the examples)
+	Count int `mapstructure:"count"`
+	// SkipMerges leaves out merge commits
+	SkipMerges bool `mapstructure:"skip_merges"`
+	// SkipAuthors leaves out commits whose author name or email contains one of these texts
+	SkipAuthors []string `mapstructure:"skip_authors"`
 }
 
 // DefaultConfig returns the default configuration
 func DefaultConfig() *Config {
 	return &Config{
+		Provider: DefaultProvider,
 		Ollama: OllamaConfig{
-			ServerURL: "http://localhost:11434/api/generate",
-			Model:     "qwen2.5-coder:7b",
+			ServerURL:    "http://localhost:11434/api/generate",
+			Model:        "qwen2.5-coder:7b",
+			API:          "generate",
+			Timeout:      2 * time.Minute,
+			Retries:      2,
+			RetryBackoff: time.Second,
+		},
+		OpenAI: OpenAIConfig{
+			ServerURL: "http://localhost:1234/v1/chat/completions",
+			Model:     "qwen2.5-coder-7b-instruct",
+		},
+		Diff: DiffConfig{
+			ContextSize:    8192,
+			ResponseTokens: 1024,
+			Exclude:        diff.DefaultExclude,
+		},
+		Summarize: SummarizeConfig{
+			Mode:        "never",
+			Concurrency: 2,
+		},
+		Redact: RedactConfig{
+			Enabled:  true,
+			OnDetect: "warn",
+		},
+		PR: PRConfig{
+			Rules: `- The title is a short summary of the pull request under 72 characters in plain text.
+- Start the description with a short paragraph explaining what the pull request changes and why.
+- Follow with a "## Changes" section listing the notable changes as bullet points.
+- Add sections for breaking changes or required migrations only if there are any.
+- Do not invent issue numbers, links or test results.`,
+		},
+		Lint: LintConfig{
+			MaxSubjectLength: 80,
+			BlankLine:        true,
+			Imperative:       true,
+			NoTrailingPeriod: true,
+			Types:            []string{"feat", "fix", "docs", "style", "refactor", "perf", "test", "build", "ci", "chore", "revert"},
+		},
+		Validate: ValidateConfig{
+			Retries: 2,
+		},
+		Prompt: PromptConfig{
+			RecentCommits: 10,
+		},
+		Examples: ExamplesConfig{
+			SkipMerges:  true,
+			SkipAuthors: []string{"[bot]", "dependabot", "renovate", "github-actions"},
 		},
 		Rules: `
 		Expected output format:
@@ -53,6 +226,7 @@ func DefaultConfig() *Config {
 }
 
 const (
+	DefaultProvider          = "ollama"
 	AppName                  = "kommit"
 	ConfigFileName           = "config"
 	StandaloneConfigFileName = ".kommit"
@@ -74,6 +248,11 @@ func readAndUnmarshalConfig() error {
 		configFile = "(unknown file)"
 	}
 
+	// Apply the defaults of the preset selected in the config file or with --preset
+	if err := applyPreset(); err != nil {
+		return fmt.Errorf("error in %s: %w", configFile, err)
+	}
+
 	// If we get here, we successfully read a config file
 	// Now unmarshal it into our config struct
 	appConfig = &Config{}
@@ -87,10 +266,66 @@ func readAndUnmarshalConfig() error {
 func Init(configFile string) error {
 	// Set defaults
 	defaults := DefaultConfig()
+	viper.SetDefault("provider", defaults.Provider)
 	viper.SetDefault("ollama.server_url", defaults.Ollama.ServerURL)
 	viper.SetDefault("ollama.model", defaults.Ollama.Model)
+	viper.SetDefault("ollama.api", defaults.Ollama.API)
+	viper.SetDefault("ollama.stream", defaults.Ollama.Stream)
+	viper.SetDefault("ollama.timeout", defaults.Ollama.Timeout)
+	viper.SetDefault("ollama.retries", defaults.Ollama.Retries)
+	viper.SetDefault("ollama.retry_backoff", defaults.Ollama.RetryBackoff)
+	viper.SetDefault("openai.server_url", defaults.OpenAI.ServerURL)
+	viper.SetDefault("openai.model", defaults.OpenAI.Model)
+	viper.SetDefault("diff.context_size", defaults.Diff.ContextSize)
+	viper.SetDefault("diff.response_tokens", defaults.Diff.ResponseTokens)
+	viper.SetDefault("diff.include", defaults.Diff.Include)
+	viper.SetDefault("diff.exclude", defaults.Diff.Exclude)
+	viper.SetDefault("summarize.mode", defaults.Summarize.Mode)
+	viper.SetDefault("summarize.concurrency", defaults.Summarize.Concurrency)
+	viper.SetDefault("redact.enabled", defaults.Redact.Enabled)
+	viper.SetDefault("redact.patterns", defaults.Redact.Patterns)
+	viper.SetDefault("redact.on_detect", defaults.Redact.OnDetect)
+	viper.SetDefault("pr.rules", defaults.PR.Rules)
+	viper.SetDefault("lint.max_subject_length", defaults.Lint.MaxSubjectLength)
+	viper.SetDefault("lint.blank_line", defaults.Lint.BlankLine)
+	viper.SetDefault("lint.imperative", defaults.Lint.Imperative)
+	viper.SetDefault("lint.no_trailing_period", defaults.Lint.NoTrailingPeriod)
+	viper.SetDefault("lint.conventional", defaults.Lint.Conventional)
+	viper.SetDefault("lint.types", defaults.Lint.Types)
+	viper.SetDefault("lint.scopes", defaults.Lint.Scopes)
+	viper.SetDefault("validate.retries", defaults.Validate.Retries)
+	viper.SetDefault("structured.enabled", defaults.Structured.Enabled)
+	viper.SetDefault("structured.template", defaults.Structured.Template)
+	viper.SetDefault("prompt.system", defaults.Prompt.System)
+	viper.SetDefault("prompt.user", defaults.Prompt.User)
+	viper.SetDefault("prompt.recent_commits", defaults.Prompt.RecentCommits)
+	viper.SetDefault("examples.count", defaults.Examples.Count)
+	viper.SetDefault("examples.skip_merges", defaults.Examples.SkipMerges)
+	viper.SetDefault("examples.skip_authors", defaults.Examples.SkipAuthors)
+	viper.SetDefault("preset", defaults.Preset)
 	viper.SetDefault("rules", defaults.Rules)
+	viper.SetDefault("extra_rules", defaults.ExtraRules)
 
+	if err := load(configFile); err != nil {
+		return err
+	}
+	appConfig.Rules = extendRules(appConfig.Rules, appConfig.ExtraRules)
+	return check(appConfig)
+}
+
+// check rejects settings with values that would otherwise silently fall back to
+// a default, e.g. a typo in a safety switch
+func check(cfg *Config) error {
+	switch cfg.Redact.OnDetect {
+	case redact.OnDetectWarn, redact.OnDetectAbort:
+	default:
+		return fmt.Errorf("invalid redact.on_detect (--on-secrets) %q, use %q or %q", cfg.Redact.OnDetect, redact.OnDetectWarn, redact.OnDetectAbort)
+	}
+	return nil
+}
+
+// load reads the config file and unmarshals it with the defaults into the configuration
+func load(configFile string) error {
 	// If config file is explicitly specified, use that
 	if configFile != "" {
 		viper.SetConfigFile(configFile)
@@ -124,7 +359,14 @@ func Init(configFile string) error {
 	if err := readAndUnmarshalConfig(); err != nil {
 		// If no config file is found, use defaults
 		if _, ok := err.(viper.ConfigFileNotFoundError); ok {
-			appConfig = DefaultConfig()
+			if err := applyPreset(); err != nil {
+				return err
+			}
+			// Unmarshal the defaults from viper so that bound flags are applied
+			appConfig = &Config{}
+			if err := viper.Unmarshal(appConfig); err != nil {
+				return fmt.Errorf("error applying defaults: %w", err)
+			}
 			return nil
 		}
 		// For any other error (including YAML parse errors), return it
@@ -188,8 +430,6 @@ func getConfigDirs() []string {
 		dirs = append(dirs, home)
 	}
 
-	fmt.Printf("Config directories: %v\n", dirs)
-
 	return dirs
 }
 
@@ -0,0 +1,27 @@
+package config
+
+import "testing"
+
+// TestCheck tests rejecting invalid settings
+func TestCheck(t *testing.T) {
+	tests := []struct {
+		name     string
+		onDetect string
+		wantErr  bool
+	}{
+		{name: "warn", onDetect: "warn"},
+		{name: "abort", onDetect: "abort"},
+		{name: "typo", onDetect: "abrot", wantErr: true},
+		{name: "empty", onDetect: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := DefaultConfig()
+			cfg.Redact.OnDetect = tt.onDetect
+			if err := check(cfg); (err != nil) != tt.wantErr {
+				t.Errorf("check() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
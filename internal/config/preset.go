@@ -0,0 +1,150 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// DefaultPreset is the name of the preset with the built-in rules
+const DefaultPreset = "default"
+
+// Preset is a named set of rules for a commit message style
+type Preset struct {
+	Description string
+	Rules       string
+	// Lint holds the lint settings matching the style, keyed like the lint section
+	// of the config file. Settings of the config file take precedence.
+	Lint map[string]any
+}
+
+// presets are the built-in presets selectable with the preset key
+var presets = map[string]Preset{
+	DefaultPreset: {
+		Description: "Plain text summary and body in the imperative mood",
+		Rules:       DefaultConfig().Rules,
+	},
+	"conventional": {
+		Description: "Conventional Commits: type(scope): description",
+		Rules: `Follow the Conventional Commits specification (https://www.conventionalcommits.org/).
+
+Format:
+<type>[optional scope]: <description>
+
+[optional body]
+
+[optional footer(s)]
+
+- Use one of these types: feat (new feature), fix (bug fix), docs, style, refactor, perf, test, build, ci, chore, revert.
+- The scope is a noun describing the part of the code base, e.g. feat(parser): ...
+- Write the description in the imperative, present tense and lower case, without a period at the end.
+- Keep the first line under 72 characters.
+- Mark breaking changes with a ! after the type or scope and a "BREAKING CHANGE: " footer.
+- Do not use emoji or markdown.`,
+		Lint: map[string]any{"conventional": true, "max_subject_length": 72},
+	},
+	"angular": {
+		Description: "Angular commit message guidelines",
+		Rules: `Follow the commit message guidelines of the Angular project.
+
+Format:
+<type>(<scope>): <short summary>
+
+<body>
+
+<footer>
+
+- Use one of these types: build, ci, docs, feat, fix, perf, refactor, test.
+- The scope is the name of the affected package or area and may be omitted for changes spanning many areas.
+- Write the summary in the imperative, present tense, do not capitalize the first letter and do not end with a period.
+- Keep the header under 100 characters.
+- Explain the motivation for the change in the body, also in the imperative, present tense.
+- Describe breaking changes in a footer starting with "BREAKING CHANGE: " followed by the migration instructions.
+- Do not use emoji or markdown.`,
+		Lint: map[string]any{
+			"conventional":       true,
+			"max_subject_length": 100,
+			"types":              []string{"build", "ci", "docs", "feat", "fix", "perf", "refactor", "test"},
+		},
+	},
+	"gitmoji": {
+		Description: "Subject starting with a gitmoji",
+		Rules: `Start the first line with the gitmoji (https://gitmoji.dev) that represents the changes best, followed by a space and a short summary.
+
+- Common gitmojis: ✨ new feature, 🐛 bug fix, 🚑️ critical hotfix, 📝 documentation, ♻️ refactoring, ⚡️ performance, ✅ tests, 🎨 structure or format, 🔥 removing code or files, 🔧 configuration, ⬆️ dependency upgrade, 🚀 deployment, 🔒️ security.
+- Use exactly one gitmoji as the emoji character, not as :code:.
+- Write the summary in the imperative, present tense, under 72 characters and without a period at the end.
+- Add a body separated by a blank line for larger changes explaining what changed and why.
+- Do not use markdown.`,
+		Lint: map[string]any{"max_subject_length": 72},
+	},
+	"kernel": {
+		Description: "Linux kernel style: subsystem: summary with Signed-off-by",
+		Rules: `Follow the commit message style of the Linux kernel.
+
+Format:
+<subsystem>: <summary>
+
+<body>
+
+Signed-off-by: <name> <email>
+
+- The subsystem is the area of the code base, e.g. the directory or module name of the changed files.
+- Write the summary in the imperative mood, e.g. "net: fix race in socket release", without a period at the end.
+- Keep the first line under 75 characters.
+- Describe the problem first, then why the change solves it, in plain text paragraphs wrapped at 75 characters.
+- Do not use emoji or markdown.
+{{- if .UserName}}
+- End the message with the line: Signed-off-by: {{.UserName}} <{{.UserEmail}}>
+{{- end}}`,
+		Lint: map[string]any{"max_subject_length": 75},
+	},
+}
+
+// PresetNames returns the sorted names of the built-in presets
+func PresetNames() []string {
+	names := make([]string, 0, len(presets))
+	for name := range presets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// GetPreset returns the preset with the given name
+func GetPreset(name string) (Preset, error) {
+	preset, ok := presets[name]
+	if !ok {
+		return Preset{}, fmt.Errorf("unknown preset %q (available: %s)", name, strings.Join(PresetNames(), ", "))
+	}
+	return preset, nil
+}
+
+// applyPreset makes the rules and the lint settings of the selected preset the
+// defaults, so that the rules and lint settings of the config file still apply
+func applyPreset() error {
+	name := viper.GetString("preset")
+	if name == "" {
+		return nil
+	}
+
+	preset, err := GetPreset(name)
+	if err != nil {
+		return err
+	}
+	viper.SetDefault("rules", preset.Rules)
+	for key, value := range preset.Lint {
+		viper.SetDefault("lint."+key, value)
+	}
+	return nil
+}
+
+// extendRules appends the extra rules to the rules
+func extendRules(rules, extra string) string {
+	if strings.TrimSpace(extra) == "" {
+		return rules
+	}
+	return strings.TrimRight(rules, "\n") + "\n" + strings.TrimRight(extra, "\n")
+}
@@ -0,0 +1,49 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestGetPreset tests looking up the built-in presets
+func TestGetPreset(t *testing.T) {
+	for _, name := range PresetNames() {
+		preset, err := GetPreset(name)
+		if err != nil {
+			t.Fatalf("GetPreset(%q) unexpected error: %v", name, err)
+		}
+		if preset.Description == "" || strings.TrimSpace(preset.Rules) == "" {
+			t.Errorf("preset %q has no description or rules", name)
+		}
+	}
+
+	if preset, _ := GetPreset(DefaultPreset); preset.Rules != DefaultConfig().Rules {
+		t.Errorf("default preset does not use the default rules")
+	}
+
+	if _, err := GetPreset("pirate"); err == nil || !strings.Contains(err.Error(), "conventional") {
+		t.Errorf("GetPreset() error = %v, want error listing the presets", err)
+	}
+}
+
+// TestExtendRules tests appending extra rules
+func TestExtendRules(t *testing.T) {
+	tests := []struct {
+		name     string
+		rules    string
+		extra    string
+		expected string
+	}{
+		{name: "no extra rules", rules: "- Rule A\n", extra: "", expected: "- Rule A\n"},
+		{name: "blank extra rules", rules: "- Rule A", extra: " \n", expected: "- Rule A"},
+		{name: "extra rules", rules: "- Rule A\n\n", extra: "- Rule B\n", expected: "- Rule A\n- Rule B"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := extendRules(tt.rules, tt.extra); result != tt.expected {
+				t.Errorf("extendRules() = %q, want %q", result, tt.expected)
+			}
+		})
+	}
+}
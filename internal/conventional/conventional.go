@@ -0,0 +1,45 @@
+package conventional
+
+import (
+	"regexp"
+	"strings"
+)
+
+// headerPattern matches the first line of a conventional commit: type(scope)!: description
+var headerPattern = regexp.MustCompile(`^([A-Za-z]+)(?:\(([^()]*)\))?(!)?: (\S.*)$`)
+
+// breakingFooterPattern matches the footer announcing a breaking change
+var breakingFooterPattern = regexp.MustCompile(`(?m)^BREAKING[ -]CHANGE: `)
+
+// Header is the parsed first line of a conventional commit message
+type Header struct {
+	Type        string
+	Scope       string
+	Breaking    bool
+	Description string
+}
+
+// ParseHeader parses the subject line of a conventional commit. It reports false
+// if the subject does not follow the format.
+func ParseHeader(subject string) (Header, bool) {
+	match := headerPattern.FindStringSubmatch(strings.TrimSpace(subject))
+	if match == nil {
+		return Header{}, false
+	}
+	return Header{
+		Type:        strings.ToLower(match[1]),
+		Scope:       match[2],
+		Breaking:    match[3] == "!",
+		Description: strings.TrimSpace(match[4]),
+	}, true
+}
+
+// IsBreaking reports whether the commit message announces a breaking change,
+// either with a ! after the type or scope or with a BREAKING CHANGE footer
+func IsBreaking(message string) bool {
+	subject, _, _ := strings.Cut(message, "\n")
+	if header, ok := ParseHeader(subject); ok && header.Breaking {
+		return true
+	}
+	return breakingFooterPattern.MatchString(message)
+}
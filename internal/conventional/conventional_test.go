@@ -0,0 +1,79 @@
+package conventional
+
+import "testing"
+
+// TestParseHeader tests parsing the subject line of conventional commits
+func TestParseHeader(t *testing.T) {
+	tests := []struct {
+		name     string
+		subject  string
+		expected Header
+		ok       bool
+	}{
+		{
+			name:     "type only",
+			subject:  "fix: handle empty diff",
+			expected: Header{Type: "fix", Description: "handle empty diff"},
+			ok:       true,
+		},
+		{
+			name:     "scope and breaking",
+			subject:  "feat(config)!: rename rules key",
+			expected: Header{Type: "feat", Scope: "config", Breaking: true, Description: "rename rules key"},
+			ok:       true,
+		},
+		{
+			name:     "type is lowercased",
+			subject:  "Docs: update README",
+			expected: Header{Type: "docs", Description: "update README"},
+			ok:       true,
+		},
+		{
+			name:    "plain subject",
+			subject: "Update README",
+		},
+		{
+			name:    "missing space",
+			subject: "fix:typo",
+		},
+		{
+			name:    "empty description",
+			subject: "fix: ",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, ok := ParseHeader(tt.subject)
+			if ok != tt.ok {
+				t.Fatalf("ParseHeader() ok = %v, want %v", ok, tt.ok)
+			}
+			if result != tt.expected {
+				t.Errorf("ParseHeader() = %+v, want %+v", result, tt.expected)
+			}
+		})
+	}
+}
+
+// TestIsBreaking tests detecting breaking changes in commit messages
+func TestIsBreaking(t *testing.T) {
+	tests := []struct {
+		name     string
+		message  string
+		expected bool
+	}{
+		{name: "exclamation mark", message: "feat!: drop Go 1.22", expected: true},
+		{name: "footer", message: "feat: new config\n\nBREAKING CHANGE: rules moved", expected: true},
+		{name: "hyphenated footer", message: "feat: new config\n\nBREAKING-CHANGE: rules moved", expected: true},
+		{name: "mentioned in body", message: "fix: typo\n\nNot a BREAKING CHANGE: at all", expected: false},
+		{name: "regular commit", message: "fix: typo", expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := IsBreaking(tt.message); result != tt.expected {
+				t.Errorf("IsBreaking() = %v, want %v", result, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,155 @@
+package diff
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// charsPerToken is a conservative estimate of characters per token for source code
+const charsPerToken = 3
+
+// minHunkLines is the minimum number of lines kept when a single hunk has to be cut
+const minHunkLines = 3
+
+// EstimateTokens returns a rough estimate of the number of tokens in s
+func EstimateTokens(s string) int {
+	return (utf8.RuneCountInString(s) + charsPerToken - 1) / charsPerToken
+}
+
+// Result is a diff that has been fitted into a token budget
+type Result struct {
+	Diff string
+	// Omitted lists the files whose content did not fit into the budget
+	Omitted []string
+	// Trimmed lists the files of which only some hunks fit into the budget
+	Trimmed []string
+	// Excluded lists the files removed from the diff by include and exclude patterns
+	Excluded []string
+}
+
+// String returns the diff followed by a note about omitted, trimmed and excluded files
+func (r *Result) String() string {
+	if len(r.Omitted) == 0 && len(r.Trimmed) == 0 {
+		return r.Diff + ExcludedNote(r.Excluded)
+	}
+
+	var sb strings.Builder
+	sb.WriteString(r.Diff)
+	sb.WriteString("\n... (diff shortened to fit the context window)\n")
+	if len(r.Trimmed) > 0 {
+		sb.WriteString(fmt.Sprintf("Files with omitted hunks: %s\n", strings.Join(r.Trimmed, ", ")))
+	}
+	if len(r.Omitted) > 0 {
+		sb.WriteString(fmt.Sprintf("Files omitted from the diff: %s\n", strings.Join(r.Omitted, ", ")))
+	}
+	sb.WriteString(ExcludedNote(r.Excluded))
+	return sb.String()
+}
+
+// Fit reduces the diff to roughly maxTokens tokens. The budget is shared fairly
+// between files: small files are kept completely and the remainder is split
+// evenly between the larger ones, which are trimmed at hunk boundaries.
+func Fit(diff string, maxTokens int) *Result {
+	if EstimateTokens(diff) <= maxTokens {
+		return &Result{Diff: diff}
+	}
+
+	files := Parse(diff)
+	grants := allocate(files, maxTokens)
+
+	result := &Result{}
+	var sb strings.Builder
+	for i, file := range files {
+		content, complete := trim(file, grants[i])
+		switch {
+		case content == "":
+			result.Omitted = append(result.Omitted, file.Path)
+		case !complete:
+			result.Trimmed = append(result.Trimmed, file.Path)
+			sb.WriteString(content)
+		default:
+			sb.WriteString(content)
+		}
+	}
+	result.Diff = sb.String()
+
+	return result
+}
+
+// allocate distributes the token budget between files using max-min fairness
+func allocate(files []File, budget int) []int {
+	costs := make([]int, len(files))
+	order := make([]int, len(files))
+	for i, file := range files {
+		costs[i] = EstimateTokens(file.String())
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return costs[order[a]] < costs[order[b]]
+	})
+
+	grants := make([]int, len(files))
+	remaining := max(budget, 0)
+	for n, i := range order {
+		share := remaining / (len(order) - n)
+		grants[i] = min(costs[i], share)
+		remaining -= grants[i]
+	}
+	return grants
+}
+
+// trim returns as many hunks of the file as fit into the token budget and
+// whether the file is complete. If not even the first hunk fits, it is cut at
+// a line boundary.
+func trim(file File, budget int) (string, bool) {
+	if EstimateTokens(file.String()) <= budget {
+		return file.String(), true
+	}
+
+	var sb strings.Builder
+	sb.WriteString(file.Header)
+	used := EstimateTokens(file.Header)
+	if used > budget {
+		return "", false
+	}
+
+	kept := 0
+	for _, hunk := range file.Hunks {
+		cost := EstimateTokens(hunk)
+		if used+cost > budget {
+			break
+		}
+		sb.WriteString(hunk)
+		used += cost
+		kept++
+	}
+
+	if kept == 0 && len(file.Hunks) > 0 {
+		lines := strings.SplitAfter(file.Hunks[0], "\n")
+		n := 0
+		for _, line := range lines {
+			cost := EstimateTokens(line)
+			if used+cost > budget {
+				break
+			}
+			sb.WriteString(line)
+			used += cost
+			n++
+		}
+		if n < minHunkLines {
+			return "", false
+		}
+		if !strings.HasSuffix(sb.String(), "\n") {
+			sb.WriteString("\n")
+		}
+		sb.WriteString("... (hunk truncated)\n")
+		kept = 1
+	}
+
+	if omitted := len(file.Hunks) - kept; omitted > 0 {
+		sb.WriteString(fmt.Sprintf("... (%d hunks omitted)\n", omitted))
+	}
+	return sb.String(), false
+}
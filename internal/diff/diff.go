@@ -0,0 +1,68 @@
+package diff
+
+import (
+	"strings"
+)
+
+// File represents the diff of a single file split into its header and hunks
+type File struct {
+	Path   string
+	Header string
+	Hunks  []string
+}
+
+// Parse splits the output of git diff into per-file diffs
+func Parse(diff string) []File {
+	var files []File
+	var current *File
+	var hunk strings.Builder
+
+	flushHunk := func() {
+		if current != nil && hunk.Len() > 0 {
+			current.Hunks = append(current.Hunks, hunk.String())
+		}
+		hunk.Reset()
+	}
+
+	for _, line := range strings.SplitAfter(diff, "\n") {
+		if line == "" {
+			continue
+		}
+
+		switch {
+		case strings.HasPrefix(line, "diff --git "):
+			flushHunk()
+			files = append(files, File{Path: pathFromGitLine(line), Header: line})
+			current = &files[len(files)-1]
+		case current == nil:
+			// Ignore anything before the first file
+		case strings.HasPrefix(line, "@@"):
+			flushHunk()
+			hunk.WriteString(line)
+		case hunk.Len() > 0:
+			hunk.WriteString(line)
+		default:
+			current.Header += line
+			if path, ok := strings.CutPrefix(line, "+++ b/"); ok {
+				current.Path = strings.TrimRight(path, "\r\n")
+			}
+		}
+	}
+	flushHunk()
+
+	return files
+}
+
+// String returns the file diff in git diff format
+func (f File) String() string {
+	return f.Header + strings.Join(f.Hunks, "")
+}
+
+// pathFromGitLine extracts the destination path from a "diff --git a/x b/x" line
+func pathFromGitLine(line string) string {
+	line = strings.TrimRight(line, "\r\n")
+	if i := strings.LastIndex(line, " b/"); i != -1 {
+		return line[i+len(" b/"):]
+	}
+	return strings.TrimPrefix(line, "diff --git ")
+}
@@ -0,0 +1,142 @@
+package diff
+
+import (
+	"strings"
+	"testing"
+)
+
+const sampleDiff = `diff --git a/go.mod b/go.mod
+index 1111111..2222222 100644
+--- a/go.mod
++++ b/go.mod
+@@ -1,3 +1,3 @@
+ module example
+-go 1.23
++go 1.24
+diff --git a/new.txt b/new.txt
+new file mode 100644
+index 0000000..3333333
+--- /dev/null
++++ b/new.txt
+@@ -0,0 +1,2 @@
++hello
++world
+@@ -10,1 +11,1 @@
+-old
++new
+diff --git a/image.png b/image.png
+index 4444444..5555555 100644
+Binary files a/image.png and b/image.png differ
+`
+
+// TestParse tests splitting a git diff into files and hunks
+func TestParse(t *testing.T) {
+	files := Parse(sampleDiff)
+
+	tests := []struct {
+		path  string
+		hunks int
+	}{
+		{path: "go.mod", hunks: 1},
+		{path: "new.txt", hunks: 2},
+		{path: "image.png", hunks: 0},
+	}
+
+	if len(files) != len(tests) {
+		t.Fatalf("Parse() returned %d files, want %d", len(files), len(tests))
+	}
+	for i, tt := range tests {
+		if files[i].Path != tt.path {
+			t.Errorf("files[%d].Path = %q, want %q", i, files[i].Path, tt.path)
+		}
+		if len(files[i].Hunks) != tt.hunks {
+			t.Errorf("files[%d] has %d hunks, want %d", i, len(files[i].Hunks), tt.hunks)
+		}
+	}
+
+	var rebuilt strings.Builder
+	for _, file := range files {
+		rebuilt.WriteString(file.String())
+	}
+	if rebuilt.String() != sampleDiff {
+		t.Errorf("joined files do not match the original diff:\n%s", rebuilt.String())
+	}
+}
+
+// TestFit tests fitting diffs into a token budget
+func TestFit(t *testing.T) {
+	large := "diff --git a/large.go b/large.go\n--- a/large.go\n+++ b/large.go\n" +
+		"@@ -1,1 +1,1 @@\n" + strings.Repeat("+first hunk line\n", 50) +
+		"@@ -100,1 +100,1 @@\n" + strings.Repeat("+second hunk line\n", 200)
+	small := "diff --git a/small.go b/small.go\n--- a/small.go\n+++ b/small.go\n@@ -1 +1 @@\n-a\n+b\n"
+
+	tests := []struct {
+		name      string
+		diff      string
+		maxTokens int
+		omitted   []string
+		trimmed   []string
+		contains  []string
+	}{
+		{
+			name:      "diff within budget is unchanged",
+			diff:      small,
+			maxTokens: 1000,
+			contains:  []string{small},
+		},
+		{
+			name:      "large file is trimmed at hunk boundary",
+			diff:      small + large,
+			maxTokens: 600,
+			trimmed:   []string{"large.go"},
+			contains:  []string{small, "first hunk line", "... (1 hunks omitted)", "Files with omitted hunks: large.go"},
+		},
+		{
+			name:      "file without room is omitted",
+			diff:      small + large,
+			maxTokens: 60,
+			omitted:   []string{"large.go"},
+			contains:  []string{small, "Files omitted from the diff: large.go"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := Fit(tt.diff, tt.maxTokens)
+			if strings.Join(result.Omitted, ",") != strings.Join(tt.omitted, ",") {
+				t.Errorf("Omitted = %v, want %v", result.Omitted, tt.omitted)
+			}
+			if strings.Join(result.Trimmed, ",") != strings.Join(tt.trimmed, ",") {
+				t.Errorf("Trimmed = %v, want %v", result.Trimmed, tt.trimmed)
+			}
+			output := result.String()
+			for _, want := range tt.contains {
+				if !strings.Contains(output, want) {
+					t.Errorf("result does not contain %q:\n%s", want, output)
+				}
+			}
+			if strings.Contains(output, "second hunk line") {
+				t.Errorf("result contains hunk that exceeds the budget")
+			}
+		})
+	}
+}
+
+// TestEstimateTokens tests that multi-byte runes are counted as characters
+func TestEstimateTokens(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int
+	}{
+		{input: "", expected: 0},
+		{input: "abc", expected: 1},
+		{input: "abcd", expected: 2},
+		{input: "äöü", expected: 1},
+	}
+
+	for _, tt := range tests {
+		if result := EstimateTokens(tt.input); result != tt.expected {
+			t.Errorf("EstimateTokens(%q) = %d, want %d", tt.input, result, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package diff
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// DefaultExclude lists lockfiles, generated and vendored files that are hidden from the model by default
+var DefaultExclude = []string{
+	"go.sum",
+	"package-lock.json",
+	"yarn.lock",
+	"pnpm-lock.yaml",
+	"Cargo.lock",
+	"poetry.lock",
+	"composer.lock",
+	"Gemfile.lock",
+	"*.min.js",
+	"*.min.css",
+	"*.pb.go",
+	"*.pb.gw.go",
+	"**/vendor/**",
+	"**/node_modules/**",
+}
+
+// Filter removes the files from the diff that match an exclude pattern or, if
+// include patterns are given, match none of them. It returns the remaining diff
+// and the paths of the removed files.
+func Filter(gitDiff string, include, exclude []string) (string, []string) {
+	if len(include) == 0 && len(exclude) == 0 {
+		return gitDiff, nil
+	}
+
+	var sb strings.Builder
+	var excluded []string
+	for _, file := range Parse(gitDiff) {
+		if Included(file.Path, include, exclude) {
+			sb.WriteString(file.String())
+		} else {
+			excluded = append(excluded, file.Path)
+		}
+	}
+	return sb.String(), excluded
+}
+
+// Included reports whether the file path passes the include and exclude patterns
+func Included(filePath string, include, exclude []string) bool {
+	if len(include) > 0 && !matchAny(include, filePath) {
+		return false
+	}
+	return !matchAny(exclude, filePath)
+}
+
+// ExcludedNote returns a note for the model listing files that changed but are not part of the diff
+func ExcludedNote(paths []string) string {
+	if len(paths) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("\nFiles changed but not shown (lockfiles, generated, vendored or excluded files): %s\n", strings.Join(paths, ", "))
+}
+
+// matchAny reports whether the file path matches one of the patterns
+func matchAny(patterns []string, filePath string) bool {
+	for _, pattern := range patterns {
+		if Match(pattern, filePath) {
+			return true
+		}
+	}
+	return false
+}
+
+// Match reports whether the slash separated file path matches the glob pattern.
+// Patterns without a slash are matched against the base name at any depth,
+// "**" matches any number of directories.
+func Match(pattern, filePath string) bool {
+	if !strings.Contains(pattern, "/") {
+		ok, _ := path.Match(pattern, path.Base(filePath))
+		return ok
+	}
+	return matchSegments(strings.Split(pattern, "/"), strings.Split(filePath, "/"))
+}
+
+// matchSegments matches path segments against pattern segments, expanding "**"
+func matchSegments(pattern, segments []string) bool {
+	if len(pattern) == 0 {
+		return len(segments) == 0
+	}
+
+	if pattern[0] == "**" {
+		for i := 0; i <= len(segments); i++ {
+			if matchSegments(pattern[1:], segments[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+
+	if len(segments) == 0 {
+		return false
+	}
+	if ok, _ := path.Match(pattern[0], segments[0]); !ok {
+		return false
+	}
+	return matchSegments(pattern[1:], segments[1:])
+}
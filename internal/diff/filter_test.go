@@ -0,0 +1,77 @@
+package diff
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestMatch tests glob matching of file paths
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		pattern  string
+		path     string
+		expected bool
+	}{
+		{pattern: "go.sum", path: "go.sum", expected: true},
+		{pattern: "go.sum", path: "tools/go.sum", expected: true},
+		{pattern: "*.pb.go", path: "api/v1/service.pb.go", expected: true},
+		{pattern: "*.pb.go", path: "api/v1/service.go", expected: false},
+		{pattern: "**/vendor/**", path: "vendor/github.com/x/y.go", expected: true},
+		{pattern: "**/vendor/**", path: "tools/vendor/a.go", expected: true},
+		{pattern: "**/vendor/**", path: "vendored.go", expected: false},
+		{pattern: "docs/*.md", path: "docs/index.md", expected: true},
+		{pattern: "docs/*.md", path: "docs/api/index.md", expected: false},
+		{pattern: "docs/**/*.md", path: "docs/api/index.md", expected: true},
+	}
+
+	for _, tt := range tests {
+		if result := Match(tt.pattern, tt.path); result != tt.expected {
+			t.Errorf("Match(%q, %q) = %v, want %v", tt.pattern, tt.path, result, tt.expected)
+		}
+	}
+}
+
+// TestFilter tests removing files from a diff by include and exclude patterns
+func TestFilter(t *testing.T) {
+	tests := []struct {
+		name     string
+		include  []string
+		exclude  []string
+		kept     []string
+		excluded []string
+	}{
+		{
+			name:     "exclude patterns",
+			exclude:  []string{"go.mod", "*.pb.go"},
+			kept:     []string{"new.txt", "image.png"},
+			excluded: []string{"go.mod"},
+		},
+		{
+			name:     "include patterns",
+			include:  []string{"*.txt"},
+			kept:     []string{"new.txt"},
+			excluded: []string{"go.mod", "image.png"},
+		},
+		{
+			name: "no patterns",
+			kept: []string{"go.mod", "new.txt", "image.png"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, excluded := Filter(sampleDiff, tt.include, tt.exclude)
+
+			var kept []string
+			for _, file := range Parse(result) {
+				kept = append(kept, file.Path)
+			}
+			if strings.Join(kept, ",") != strings.Join(tt.kept, ",") {
+				t.Errorf("Filter() kept %v, want %v", kept, tt.kept)
+			}
+			if strings.Join(excluded, ",") != strings.Join(tt.excluded, ",") {
+				t.Errorf("Filter() excluded %v, want %v", excluded, tt.excluded)
+			}
+		})
+	}
+}
@@ -0,0 +1,93 @@
+package git
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// EditMessageFile is the file in the git directory used to edit commit messages
+const EditMessageFile = "KOMMIT_EDITMSG"
+
+// GetEditor returns the editor git uses for commit messages. git resolves
+// $GIT_EDITOR, core.editor, $VISUAL and $EDITOR in that order.
+func GetEditor() (string, error) {
+	cmd := execCommand("git", "var", "GIT_EDITOR")
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to determine editor: %w", err)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// GetCommentChar returns the character git uses to mark comment lines in commit messages
+func GetCommentChar() string {
+	cmd := execCommand("git", "config", "core.commentChar")
+	output, err := cmd.Output()
+	commentChar := strings.TrimSpace(string(output))
+	if err != nil || commentChar == "" || commentChar == "auto" {
+		return "#"
+	}
+	return commentChar
+}
+
+// EditMessage opens the message in the editor configured for git and returns
+// the edited message. Comment lines and surplus whitespace are removed the way
+// git does it; an empty result means the user aborted.
+func EditMessage(message string) (string, error) {
+	editor, err := GetEditor()
+	if err != nil {
+		return "", err
+	}
+
+	cmd := execCommand("git", "rev-parse", "--git-path", EditMessageFile)
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate git directory: %w", err)
+	}
+	path := strings.TrimSpace(string(output))
+
+	commentChar := GetCommentChar()
+	content := fmt.Sprintf("%s\n\n%s Please edit the commit message. Lines starting\n%s with '%s' will be ignored, and an empty message aborts the commit.\n",
+		message, commentChar, commentChar, commentChar)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		return "", fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	defer os.Remove(path)
+
+	// Run the editor through the shell like git does, so editors with arguments work
+	editCmd := execCommand("sh", "-c", editor+` "$@"`, editor, path)
+	editCmd.Stdin = os.Stdin
+	editCmd.Stdout = os.Stdout
+	editCmd.Stderr = os.Stderr
+	if err := editCmd.Run(); err != nil {
+		return "", fmt.Errorf("editor %q failed: %w", editor, err)
+	}
+
+	edited, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return StripComments(string(edited))
+}
+
+// scissors marks the start of the diff git appends to the message with commit --verbose
+const scissors = " ------------------------ >8 ------------------------\n"
+
+// StripComments removes comment lines, surplus whitespace and everything below a
+// scissors line from a commit message using git stripspace
+func StripComments(message string) (string, error) {
+	if i := strings.Index(message, GetCommentChar()+scissors); i != -1 && (i == 0 || message[i-1] == '\n') {
+		message = message[:i]
+	}
+
+	cmd := execCommand("git", "stripspace", "--strip-comments")
+	cmd.Stdin = strings.NewReader(message)
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("failed to clean up message: %w", err)
+	}
+	return strings.TrimSpace(out.String()), nil
+}
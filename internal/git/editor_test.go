@@ -0,0 +1,45 @@
+package git
+
+import "testing"
+
+// TestStripComments tests removing comment lines and surplus whitespace from edited messages
+func TestStripComments(t *testing.T) {
+	tests := []struct {
+		name     string
+		message  string
+		expected string
+	}{
+		{
+			name:     "comment lines are removed",
+			message:  "Add feature\n\n# Please edit the commit message.\n",
+			expected: "Add feature",
+		},
+		{
+			name:     "body is kept",
+			message:  "Add feature\n\n\n\nExplain why\n# comment\n",
+			expected: "Add feature\n\nExplain why",
+		},
+		{
+			name:     "diff below scissors",
+			message:  "Add feature\n# Please edit\n# ------------------------ >8 ------------------------\n# Do not modify or remove the line above.\ndiff --git a/a b/a\n",
+			expected: "Add feature",
+		},
+		{
+			name:     "only comments",
+			message:  "# nothing\n\n",
+			expected: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := StripComments(tt.message)
+			if err != nil {
+				t.Fatalf("StripComments() unexpected error: %v", err)
+			}
+			if result != tt.expected {
+				t.Errorf("StripComments() = %q, want %q", result, tt.expected)
+			}
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package git
 
 import (
+	"fmt"
 	"os/exec"
 	"strings"
 )
@@ -22,7 +23,20 @@ func GetGitStatus() (string, error) {
 // GetGitDiff returns the diff of changes that are currently staged for commit.
 // It only shows changes that have been added to the staging area with 'git add'.
 func GetGitDiff() (string, error) {
-	cmd := execCommand("git", "diff", "--cached")
+	return GetDiff("--cached")
+}
+
+// GetDiff returns the diff selected by the git diff arguments, e.g. "--cached", "HEAD~1"
+// for the staged changes together with the changes of the last commit.
+func GetDiff(diffArgs ...string) (string, error) {
+	return GetPathsDiff(nil, diffArgs...)
+}
+
+// GetPathsDiff returns the diff selected by the git diff arguments limited to the given paths
+func GetPathsDiff(paths []string, diffArgs ...string) (string, error) {
+	args := append([]string{"diff"}, diffArgs...)
+	args = append(append(args, "--"), paths...)
+	cmd := execCommand("git", args...)
 	output, err := cmd.Output()
 	if err != nil {
 		return "", err
@@ -30,6 +44,33 @@ func GetGitDiff() (string, error) {
 	return string(output), nil
 }
 
+// GetParent returns the first parent of the revision. For root commits it returns
+// the empty tree, so that diffs against it show the whole commit.
+func GetParent(rev string) (string, error) {
+	cmd := execCommand("git", "rev-parse", "--verify", "--quiet", rev+"^{commit}")
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("unknown revision %s", rev)
+	}
+
+	cmd = execCommand("git", "rev-parse", "--verify", "--quiet", rev+"^")
+	output, err := cmd.Output()
+	if err != nil {
+		return GetEmptyTree()
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// GetEmptyTree returns the object name of the empty tree in the object format of the repository
+func GetEmptyTree() (string, error) {
+	cmd := execCommand("git", "hash-object", "-t", "tree", "--stdin")
+	cmd.Stdin = strings.NewReader("")
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to determine empty tree: %w", err)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
 // HasStagedChanges checks if there are any staged changes in the git repository.
 // It returns true if there are staged changes, false otherwise.
 // If there is an error running the git command, it returns false and the error.
@@ -65,6 +106,12 @@ func CommitChanges(message string) error {
 	return cmd.Run()
 }
 
+// AmendCommit replaces the last commit with the staged changes and the given message
+func AmendCommit(message string) error {
+	cmd := execCommand("git", "commit", "--amend", "-m", message)
+	return cmd.Run()
+}
+
 // GetGitDir returns the absolute path to the root directory of the current git repository.
 // Returns an empty string if not in a git repository.
 func GetGitDir() (string, error) {
@@ -117,3 +164,20 @@ func PushCurrentBranch() error {
 	pushCmd := execCommand("git", "push", "--set-upstream", "origin", branch)
 	return pushCmd.Run()
 }
+
+// GetUser returns the name and the email of the configured git user, empty if not configured
+func GetUser() (name, email string) {
+	nameOut, _ := execCommand("git", "config", "user.name").Output()
+	emailOut, _ := execCommand("git", "config", "user.email").Output()
+	return strings.TrimSpace(string(nameOut)), strings.TrimSpace(string(emailOut))
+}
+
+// GetHooksDir returns the directory git runs hooks from, honoring core.hooksPath
+func GetHooksDir() (string, error) {
+	cmd := execCommand("git", "rev-parse", "--git-path", "hooks")
+	output, err := cmd.Output()
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(output)), nil
+}
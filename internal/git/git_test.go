@@ -2,6 +2,7 @@ package git
 
 import (
 	"os/exec"
+	"strings"
 	"testing"
 )
 
@@ -121,3 +122,88 @@ func TestIsGitRepo(t *testing.T) {
 		})
 	}
 }
+
+// TestGetDiff tests that the diff arguments are passed to git diff
+func TestGetDiff(t *testing.T) {
+	// Save original execCommand and restore it after the test
+	originalExecCommand := execCommand
+	defer func() { execCommand = originalExecCommand }()
+
+	var got []string
+	execCommand = func(name string, arg ...string) *exec.Cmd {
+		got = append([]string{name}, arg...)
+		return exec.Command("echo", "diff")
+	}
+
+	if _, err := GetDiff("--cached", "HEAD~1"); err != nil {
+		t.Fatalf("GetDiff() unexpected error: %v", err)
+	}
+
+	expected := "git diff --cached HEAD~1 --"
+	if strings.Join(got, " ") != expected {
+		t.Errorf("GetDiff() ran %q, want %q", strings.Join(got, " "), expected)
+	}
+}
+
+// TestGetParent tests resolving the parent of a revision
+func TestGetParent(t *testing.T) {
+	// Save original execCommand and restore it after the test
+	originalExecCommand := execCommand
+	defer func() { execCommand = originalExecCommand }()
+
+	tests := []struct {
+		name     string
+		setup    func()
+		expected string
+		hasError bool
+	}{
+		{
+			name: "commit with parent",
+			setup: func() {
+				execCommand = func(name string, arg ...string) *exec.Cmd {
+					return exec.Command("echo", "abc123")
+				}
+			},
+			expected: "abc123",
+		},
+		{
+			name: "root commit",
+			setup: func() {
+				execCommand = func(name string, arg ...string) *exec.Cmd {
+					switch arg[len(arg)-1] {
+					case "HEAD^":
+						return exec.Command("false")
+					case "--stdin":
+						return exec.Command("echo", "emptytree")
+					default:
+						return exec.Command("true")
+					}
+				}
+			},
+			expected: "emptytree",
+		},
+		{
+			name: "unknown revision",
+			setup: func() {
+				execCommand = func(name string, arg ...string) *exec.Cmd {
+					return exec.Command("false")
+				}
+			},
+			hasError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.setup()
+
+			result, err := GetParent("HEAD")
+			if (err != nil) != tt.hasError {
+				t.Fatalf("GetParent() error = %v, hasError %v", err, tt.hasError)
+			}
+			if result != tt.expected {
+				t.Errorf("GetParent() = %q, want %q", result, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,143 @@
+package git
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Commit represents a single commit of the history
+type Commit struct {
+	Hash        string
+	Parents     []string
+	AuthorName  string
+	AuthorEmail string
+	Message     string
+}
+
+// Subject returns the first line of the commit message
+func (c Commit) Subject() string {
+	subject, _, _ := strings.Cut(c.Message, "\n")
+	return subject
+}
+
+// IsMerge reports whether the commit has more than one parent
+func (c Commit) IsMerge() bool {
+	return len(c.Parents) > 1
+}
+
+// GeneratedPrefixes are the subject prefixes of commit messages written by git itself
+var GeneratedPrefixes = []string{"Merge ", "Revert \"", "fixup! ", "squash! ", "amend! "}
+
+// IsGeneratedSubject reports whether the subject belongs to a commit message written
+// by git, e.g. of a merge, a revert or a fixup! commit
+func IsGeneratedSubject(subject string) bool {
+	for _, prefix := range GeneratedPrefixes {
+		if strings.HasPrefix(subject, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// Separators of the fields and records in the output of git log
+const (
+	fieldSeparator  = "\x1f"
+	recordSeparator = "\x1e"
+)
+
+// GetCommits returns the commits selected by the git log arguments, e.g. "main..HEAD",
+// oldest first
+func GetCommits(logArgs ...string) ([]Commit, error) {
+	args := []string{"log", "--reverse", "--format=%H" + fieldSeparator + "%P" + fieldSeparator + "%an" + fieldSeparator + "%ae" + fieldSeparator + "%B" + recordSeparator}
+	cmd := execCommand("git", append(append(args, logArgs...), "--")...)
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list commits: %w", err)
+	}
+	return parseCommits(string(output)), nil
+}
+
+// parseCommits parses the records written by GetCommits
+func parseCommits(output string) []Commit {
+	var commits []Commit
+	for _, record := range strings.Split(output, recordSeparator) {
+		fields := strings.SplitN(strings.TrimLeft(record, "\n"), fieldSeparator, 5)
+		if len(fields) < 5 {
+			continue
+		}
+		commits = append(commits, Commit{
+			Hash:        fields[0],
+			Parents:     strings.Fields(fields[1]),
+			AuthorName:  fields[2],
+			AuthorEmail: fields[3],
+			Message:     strings.TrimSpace(fields[4]),
+		})
+	}
+	return commits
+}
+
+// IsAncestor reports whether the commit is an ancestor of (or equal to) the descendant
+func IsAncestor(commit, descendant string) bool {
+	cmd := execCommand("git", "merge-base", "--is-ancestor", commit, descendant)
+	return cmd.Run() == nil
+}
+
+// GetMergeBase returns the best common ancestor of the two revisions
+func GetMergeBase(a, b string) (string, error) {
+	cmd := execCommand("git", "merge-base", a, b)
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("no common ancestor of %s and %s", a, b)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// GetDefaultBranch returns the branch work is usually based on: the default
+// branch of origin if known, otherwise a local main or master branch
+func GetDefaultBranch() (string, error) {
+	cmd := execCommand("git", "rev-parse", "--abbrev-ref", "origin/HEAD")
+	if output, err := cmd.Output(); err == nil {
+		return strings.TrimSpace(string(output)), nil
+	}
+
+	for _, branch := range []string{"main", "master"} {
+		cmd = execCommand("git", "rev-parse", "--verify", "--quiet", "refs/heads/"+branch)
+		if cmd.Run() == nil {
+			return branch, nil
+		}
+	}
+	return "", fmt.Errorf("failed to determine the default branch")
+}
+
+// GetHead returns the commit hash of HEAD
+func GetHead() (string, error) {
+	cmd := execCommand("git", "rev-parse", "HEAD")
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve HEAD: %w", err)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// SquashCommits replaces the commits after base with a single commit with the
+// given message. HEAD is restored if the commit fails.
+func SquashCommits(base, message string) error {
+	head, err := GetHead()
+	if err != nil {
+		return err
+	}
+
+	cmd := execCommand("git", "reset", "--soft", base)
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to reset to %s: %w", base, err)
+	}
+
+	if err := CommitChanges(message); err != nil {
+		restore := execCommand("git", "reset", "--soft", head)
+		if restoreErr := restore.Run(); restoreErr != nil {
+			return fmt.Errorf("failed to commit: %w (restoring %s failed: %v)", err, head, restoreErr)
+		}
+		return fmt.Errorf("failed to commit: %w", err)
+	}
+	return nil
+}
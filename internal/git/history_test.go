@@ -0,0 +1,138 @@
+package git
+
+import (
+	"os/exec"
+	"reflect"
+	"testing"
+)
+
+// TestParseCommits tests parsing the commit records written by git log
+func TestParseCommits(t *testing.T) {
+	tests := []struct {
+		name     string
+		output   string
+		expected []Commit
+	}{
+		{
+			name:     "no commits",
+			output:   "",
+			expected: nil,
+		},
+		{
+			name:   "root commit and merge",
+			output: "aaa\x1f\x1fJane Doe\x1fjane@example.com\x1fInitial commit\n\x1e\nbbb\x1fccc ddd\x1fdependabot[bot]\x1fbot@example.com\x1fMerge branch 'feature'\n\nDetails\n\x1e\n",
+			expected: []Commit{
+				{Hash: "aaa", Parents: []string{}, AuthorName: "Jane Doe", AuthorEmail: "jane@example.com", Message: "Initial commit"},
+				{Hash: "bbb", Parents: []string{"ccc", "ddd"}, AuthorName: "dependabot[bot]", AuthorEmail: "bot@example.com", Message: "Merge branch 'feature'\n\nDetails"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := parseCommits(tt.output)
+			if !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("parseCommits() = %#v, want %#v", result, tt.expected)
+			}
+		})
+	}
+}
+
+// TestCommitSubject tests extracting the subject line of a commit message
+func TestCommitSubject(t *testing.T) {
+	tests := []struct {
+		name     string
+		message  string
+		expected string
+	}{
+		{name: "subject only", message: "Fix typo", expected: "Fix typo"},
+		{name: "subject and body", message: "Fix typo\n\nIn the README", expected: "Fix typo"},
+		{name: "empty", message: "", expected: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := (Commit{Message: tt.message}).Subject(); result != tt.expected {
+				t.Errorf("Subject() = %q, want %q", result, tt.expected)
+			}
+		})
+	}
+}
+
+// TestGetDefaultBranch tests the fallbacks for determining the default branch
+func TestGetDefaultBranch(t *testing.T) {
+	// Save original execCommand and restore it after the test
+	originalExecCommand := execCommand
+	defer func() { execCommand = originalExecCommand }()
+
+	tests := []struct {
+		name     string
+		branches map[string]bool
+		expected string
+		hasError bool
+	}{
+		{
+			name:     "origin head",
+			branches: map[string]bool{"origin/HEAD": true, "refs/heads/master": true},
+			expected: "origin/main",
+		},
+		{
+			name:     "local main",
+			branches: map[string]bool{"refs/heads/main": true, "refs/heads/master": true},
+			expected: "main",
+		},
+		{
+			name:     "local master",
+			branches: map[string]bool{"refs/heads/master": true},
+			expected: "master",
+		},
+		{
+			name:     "unknown",
+			branches: map[string]bool{},
+			hasError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			execCommand = func(name string, arg ...string) *exec.Cmd {
+				if !tt.branches[arg[len(arg)-1]] {
+					return exec.Command("false")
+				}
+				return exec.Command("echo", "origin/main")
+			}
+
+			result, err := GetDefaultBranch()
+			if (err != nil) != tt.hasError {
+				t.Fatalf("GetDefaultBranch() error = %v, hasError %v", err, tt.hasError)
+			}
+			if result != tt.expected {
+				t.Errorf("GetDefaultBranch() = %q, want %q", result, tt.expected)
+			}
+		})
+	}
+}
+
+// TestIsGeneratedSubject tests detecting subjects of messages written by git
+func TestIsGeneratedSubject(t *testing.T) {
+	tests := []struct {
+		subject  string
+		expected bool
+	}{
+		{subject: "Merge branch 'main' into feature", expected: true},
+		{subject: `Revert "Add lint command"`, expected: true},
+		{subject: "fixup! Add lint command", expected: true},
+		{subject: "squash! Add lint command", expected: true},
+		{subject: "amend! Add lint command", expected: true},
+		{subject: "Add lint command", expected: false},
+		{subject: "Merged lint rules", expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.subject, func(t *testing.T) {
+			if result := IsGeneratedSubject(tt.subject); result != tt.expected {
+				t.Errorf("IsGeneratedSubject(%q) = %v, want %v", tt.subject, result, tt.expected)
+			}
+		})
+	}
+}
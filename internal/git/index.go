@@ -0,0 +1,46 @@
+package git
+
+import (
+	"fmt"
+	"strings"
+)
+
+// WriteIndexTree saves the staged state as a tree object and returns its hash,
+// so that the index can be restored with RestoreIndex
+func WriteIndexTree() (string, error) {
+	cmd := execCommand("git", "write-tree")
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to save the index: %w", err)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// RestoreIndex replaces the index with the tree saved by WriteIndexTree.
+// The working tree is not touched.
+func RestoreIndex(tree string) error {
+	cmd := execCommand("git", "read-tree", tree)
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to restore the index from %s: %w", tree, err)
+	}
+
+	// read-tree drops the cached file stats, refresh them so that git status stays fast
+	cmd = execCommand("git", "update-index", "-q", "--refresh")
+	_ = cmd.Run()
+	return nil
+}
+
+// StageFromTree resets the index to HEAD and stages the paths as recorded in the
+// tree. Paths missing from the tree are removed from the index.
+func StageFromTree(tree string, paths []string) error {
+	cmd := execCommand("git", "reset", "-q")
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to reset the index: %w", err)
+	}
+
+	cmd = execCommand("git", append([]string{"reset", "-q", tree, "--"}, paths...)...)
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to stage %s: %w", strings.Join(paths, ", "), err)
+	}
+	return nil
+}
@@ -0,0 +1,91 @@
+package git
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// RewordCommits rewrites the messages of commits between base and HEAD with a
+// scripted interactive rebase. messages maps the full hashes of the commits to
+// reword to their new message, all other commits are picked unchanged. An empty
+// base rebases the whole history (--root).
+func RewordCommits(base string, messages map[string]string) error {
+	revRange := "HEAD"
+	if base != "" {
+		revRange = base + "..HEAD"
+	}
+	commits, err := GetCommits(revRange)
+	if err != nil {
+		return err
+	}
+
+	known := make(map[string]bool, len(commits))
+	for _, commit := range commits {
+		if commit.IsMerge() {
+			return fmt.Errorf("cannot reword history containing merge commit %s", commit.Hash)
+		}
+		known[commit.Hash] = true
+	}
+
+	dir, err := os.MkdirTemp("", "kommit-reword-")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary directory: %w", err)
+	}
+	defer os.RemoveAll(dir)
+
+	messageFiles := make(map[string]string, len(messages))
+	for hash, message := range messages {
+		if !known[hash] {
+			return fmt.Errorf("commit %s is not between %s and HEAD", hash, base)
+		}
+		path := filepath.Join(dir, hash)
+		if err := os.WriteFile(path, []byte(message+"\n"), 0o600); err != nil {
+			return fmt.Errorf("failed to write %s: %w", path, err)
+		}
+		messageFiles[hash] = path
+	}
+
+	todoPath := filepath.Join(dir, "git-rebase-todo")
+	if err := os.WriteFile(todoPath, []byte(rewordTodo(commits, messageFiles)), 0o600); err != nil {
+		return fmt.Errorf("failed to write %s: %w", todoPath, err)
+	}
+
+	args := []string{"rebase", "--interactive"}
+	if base == "" {
+		args = append(args, "--root")
+	} else {
+		args = append(args, base)
+	}
+
+	// git lets the sequence editor edit the todo list, replace it with the prepared one
+	cmd := execCommand("git", args...)
+	cmd.Env = append(os.Environ(),
+		"GIT_SEQUENCE_EDITOR=cp "+ShellQuote(todoPath),
+		"GIT_EDITOR=true",
+	)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("rebase failed: %w\n%s", err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// rewordTodo returns the todo list of an interactive rebase that picks the commits
+// and amends the message of those with a message file right after picking them
+func rewordTodo(commits []Commit, messageFiles map[string]string) string {
+	var sb strings.Builder
+	for _, commit := range commits {
+		fmt.Fprintf(&sb, "pick %s %s\n", commit.Hash, commit.Subject())
+		if path, ok := messageFiles[commit.Hash]; ok {
+			fmt.Fprintf(&sb, "exec git commit --amend --allow-empty --no-verify --file %s\n", ShellQuote(path))
+		}
+	}
+	return sb.String()
+}
+
+// ShellQuote quotes the argument for /bin/sh with single quotes, so that it is
+// not expanded
+func ShellQuote(arg string) string {
+	return "'" + strings.ReplaceAll(arg, "'", `'\''`) + "'"
+}
@@ -0,0 +1,41 @@
+package git
+
+import "testing"
+
+// TestRewordTodo tests the todo list of the scripted rebase
+func TestRewordTodo(t *testing.T) {
+	commits := []Commit{
+		{Hash: "aaa", Message: "wip"},
+		{Hash: "bbb", Message: "Add parser\n\nWith tests"},
+		{Hash: "ccc", Message: "fix"},
+	}
+
+	tests := []struct {
+		name         string
+		messageFiles map[string]string
+		expected     string
+	}{
+		{
+			name:         "nothing to reword",
+			messageFiles: nil,
+			expected:     "pick aaa wip\npick bbb Add parser\npick ccc fix\n",
+		},
+		{
+			name:         "reword some commits",
+			messageFiles: map[string]string{"aaa": "/tmp/aaa", "ccc": "/tmp/it's"},
+			expected: "pick aaa wip\n" +
+				"exec git commit --amend --allow-empty --no-verify --file '/tmp/aaa'\n" +
+				"pick bbb Add parser\n" +
+				"pick ccc fix\n" +
+				"exec git commit --amend --allow-empty --no-verify --file '/tmp/it'\\''s'\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := rewordTodo(commits, tt.messageFiles); result != tt.expected {
+				t.Errorf("rewordTodo() = %q, want %q", result, tt.expected)
+			}
+		})
+	}
+}
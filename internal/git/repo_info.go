@@ -2,7 +2,8 @@ package git
 
 import (
 	"fmt"
-	"os/exec"
+	"regexp"
+	"strconv"
 	"strings"
 )
 
@@ -19,14 +20,30 @@ type FileChange struct {
 	Status   string
 	FilePath string
 	FileType string
+	// OldPath is the previous path of renamed and copied files
+	OldPath string
+}
+
+// Paths returns the paths touched by the change, including the previous path of renamed files
+func (c FileChange) Paths() []string {
+	if c.OldPath == "" {
+		return []string{c.FilePath}
+	}
+	return []string{c.OldPath, c.FilePath}
 }
 
 // GetRepoContext returns the current repository context including branch, changes, etc.
 func GetRepoContext() (*RepoContext, error) {
+	return GetRepoContextForDiff("--staged")
+}
+
+// GetRepoContextForDiff returns the repository context for the changes selected
+// by the git diff arguments, e.g. "--staged" or "--staged", "HEAD~1"
+func GetRepoContextForDiff(diffArgs ...string) (*RepoContext, error) {
 	ctx := &RepoContext{}
 
 	// Get current branch name
-	branchCmd := exec.Command("git", "branch", "--show-current")
+	branchCmd := execCommand("git", "branch", "--show-current")
 	branchOut, err := branchCmd.Output()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get branch name: %w", err)
@@ -34,7 +51,7 @@ func GetRepoContext() (*RepoContext, error) {
 	ctx.BranchName = strings.TrimSpace(string(branchOut))
 
 	// Get number of changed files
-	countCmd := exec.Command("git", "diff", "--staged", "--name-only")
+	countCmd := execCommand("git", diffCommand(diffArgs, "--name-only")...)
 	countOut, err := countCmd.Output()
 	if err != nil {
 		return nil, fmt.Errorf("failed to count changed files: %w", err)
@@ -47,7 +64,7 @@ func GetRepoContext() (*RepoContext, error) {
 	}
 
 	// Get change summary
-	summaryCmd := exec.Command("git", "diff", "--staged", "--stat")
+	summaryCmd := execCommand("git", diffCommand(diffArgs, "--stat")...)
 	summaryOut, err := summaryCmd.Output()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get change summary: %w", err)
@@ -55,7 +72,7 @@ func GetRepoContext() (*RepoContext, error) {
 	ctx.ChangeSummary = string(summaryOut)
 
 	// Get detailed file changes
-	changesCmd := exec.Command("git", "diff", "--staged", "--name-status")
+	changesCmd := execCommand("git", diffCommand(diffArgs, "--name-status")...)
 	changesOut, err := changesCmd.Output()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get file changes: %w", err)
@@ -66,13 +83,17 @@ func GetRepoContext() (*RepoContext, error) {
 		if change == "" {
 			continue
 		}
-		// Split on tab to separate status and file path
-		parts := strings.Fields(change)
+		// Split on tab to separate status and file path, renames and copies list the old and the new path
+		parts := strings.Split(change, "\t")
 		if len(parts) < 2 {
 			continue
 		}
 		status := parts[0]
-		filePath := parts[1]
+		filePath := parts[len(parts)-1]
+		oldPath := ""
+		if len(parts) > 2 {
+			oldPath = parts[1]
+		}
 
 		// Get file extension
 		fileType := ""
@@ -84,12 +105,39 @@ func GetRepoContext() (*RepoContext, error) {
 			Status:   status,
 			FilePath: filePath,
 			FileType: fileType,
+			OldPath:  oldPath,
 		})
 	}
 
 	return ctx, nil
 }
 
+// diffCommand returns the arguments of a git diff command with the diff arguments and the output format
+func diffCommand(diffArgs []string, format string) []string {
+	args := append([]string{"diff", format}, diffArgs...)
+	return append(args, "--")
+}
+
+// statPattern matches the insertions and deletions of the summary line of git diff --stat
+var statPattern = regexp.MustCompile(`(\d+) (insertion|deletion)s?\([+-]\)`)
+
+// Stats returns the number of inserted and deleted lines from the change summary
+func (r *RepoContext) Stats() (insertions, deletions int) {
+	summary := strings.TrimSpace(r.ChangeSummary)
+	if i := strings.LastIndex(summary, "\n"); i != -1 {
+		summary = summary[i+1:]
+	}
+	for _, match := range statPattern.FindAllStringSubmatch(summary, -1) {
+		count, _ := strconv.Atoi(match[1])
+		if match[2] == "insertion" {
+			insertions = count
+		} else {
+			deletions = count
+		}
+	}
+	return insertions, deletions
+}
+
 // String returns a formatted string representation of the repository context
 func (r *RepoContext) String() string {
 	var sb strings.Builder
@@ -0,0 +1,44 @@
+package git
+
+import "testing"
+
+// TestRepoContextStats tests reading the line counts from the change summary
+func TestRepoContextStats(t *testing.T) {
+	tests := []struct {
+		name       string
+		summary    string
+		insertions int
+		deletions  int
+	}{
+		{
+			name:       "insertions and deletions",
+			summary:    " cmd/root.go | 12 +++++++---\n main.go     |  3 +++\n 2 files changed, 10 insertions(+), 5 deletions(-)\n",
+			insertions: 10,
+			deletions:  5,
+		},
+		{
+			name:       "single insertion",
+			summary:    " a.txt | 1 +\n 1 file changed, 1 insertion(+)\n",
+			insertions: 1,
+		},
+		{
+			name:      "deletions only",
+			summary:   " a.txt | 4 ----\n 1 file changed, 4 deletions(-)\n",
+			deletions: 4,
+		},
+		{
+			name:    "empty summary",
+			summary: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repoCtx := &RepoContext{ChangeSummary: tt.summary}
+			insertions, deletions := repoCtx.Stats()
+			if insertions != tt.insertions || deletions != tt.deletions {
+				t.Errorf("Stats() = %d, %d, want %d, %d", insertions, deletions, tt.insertions, tt.deletions)
+			}
+		})
+	}
+}
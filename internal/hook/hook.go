@@ -0,0 +1,104 @@
+package hook
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/madflow/kommit/internal/git"
+)
+
+// Name is the git hook kommit installs
+const Name = "prepare-commit-msg"
+
+// marker identifies hooks written by kommit
+const marker = "# Installed by kommit"
+
+// ErrForeignHook is returned when a hook exists that was not installed by kommit
+var ErrForeignHook = errors.New("hook exists and was not installed by kommit")
+
+// Script returns the prepare-commit-msg hook that calls the given kommit executable.
+// A failing kommit never blocks the commit.
+func Script(executable string) string {
+	return fmt.Sprintf(`#!/bin/sh
+%s
+# Fills the commit message with a generated message when none was given.
+KOMMIT=kommit
+command -v "$KOMMIT" >/dev/null 2>&1 || KOMMIT=%s
+"$KOMMIT" hook run "$@" || true
+`, marker, git.ShellQuote(executable))
+}
+
+// Install writes the prepare-commit-msg hook into the hooks directory and returns
+// its path. An existing hook is only replaced if it was written by kommit or force is set.
+func Install(hooksDir, executable string, force bool) (string, error) {
+	path := filepath.Join(hooksDir, Name)
+	if !force {
+		if installed, err := isInstalled(path); err == nil && !installed {
+			return "", fmt.Errorf("%s: %w (use --force to replace it)", path, ErrForeignHook)
+		}
+	}
+
+	if err := os.MkdirAll(hooksDir, 0o755); err != nil {
+		return "", fmt.Errorf("failed to create hooks directory: %w", err)
+	}
+	if err := os.WriteFile(path, []byte(Script(executable)), 0o755); err != nil {
+		return "", fmt.Errorf("failed to write hook: %w", err)
+	}
+	// WriteFile keeps the mode of existing files
+	if err := os.Chmod(path, 0o755); err != nil {
+		return "", fmt.Errorf("failed to make hook executable: %w", err)
+	}
+	return path, nil
+}
+
+// Uninstall removes the prepare-commit-msg hook if it was written by kommit and returns its path
+func Uninstall(hooksDir string) (string, error) {
+	path := filepath.Join(hooksDir, Name)
+	installed, err := isInstalled(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read hook: %w", err)
+	}
+	if !installed {
+		return "", fmt.Errorf("%s: %w", path, ErrForeignHook)
+	}
+	if err := os.Remove(path); err != nil {
+		return "", fmt.Errorf("failed to remove hook: %w", err)
+	}
+	return path, nil
+}
+
+// isInstalled reports whether the hook at path was written by kommit
+func isInstalled(path string) (bool, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return false, err
+	}
+	return strings.Contains(string(content), marker), nil
+}
+
+// ShouldFill reports whether the hook should generate a message. git passes the
+// source of the message as second argument: message (-m/-F), template, merge,
+// squash or commit (--amend, -c, -C). Only commits without any message and
+// without content besides comments are filled.
+func ShouldFill(source, content string) bool {
+	if source != "" && source != "template" {
+		return false
+	}
+	return strings.TrimSpace(content) == ""
+}
+
+// FillMessage writes the message in front of the existing content of the commit message file
+func FillMessage(path, message string) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	filled := strings.TrimSpace(message) + "\n" + string(content)
+	if err := os.WriteFile(path, []byte(filled), 0o644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
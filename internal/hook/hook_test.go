@@ -0,0 +1,127 @@
+package hook
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestInstallUninstall tests writing and removing the hook
+func TestInstallUninstall(t *testing.T) {
+	hooksDir := filepath.Join(t.TempDir(), "hooks")
+
+	path, err := Install(hooksDir, "/usr/local/bin/kommit", false)
+	if err != nil {
+		t.Fatalf("Install() unexpected error: %v", err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("hook was not written: %v", err)
+	}
+	if info.Mode().Perm()&0o111 == 0 {
+		t.Errorf("hook is not executable: %v", info.Mode())
+	}
+
+	// Reinstalling our own hook is allowed
+	if _, err := Install(hooksDir, "/usr/local/bin/kommit", false); err != nil {
+		t.Errorf("Install() over own hook unexpected error: %v", err)
+	}
+
+	if _, err := Uninstall(hooksDir); err != nil {
+		t.Fatalf("Uninstall() unexpected error: %v", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("hook still exists after Uninstall()")
+	}
+}
+
+// TestScriptQuoting tests that the shell does not expand the path of the executable
+func TestScriptQuoting(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "it's $HOME `id`")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	output := filepath.Join(t.TempDir(), "args")
+	executable := filepath.Join(dir, "kommit")
+	if err := os.WriteFile(executable, []byte("#!/bin/sh\necho \"$@\" > '"+output+"'\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := exec.Command("/bin/sh", "-c", Script(executable), "hook", "COMMIT_EDITMSG")
+	cmd.Env = []string{"PATH=/usr/bin:/bin"}
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("hook failed: %v\n%s", err, out)
+	}
+	args, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatalf("executable was not called: %v", err)
+	}
+	if strings.TrimSpace(string(args)) != "hook run COMMIT_EDITMSG" {
+		t.Errorf("executable called with %q", args)
+	}
+}
+
+// TestInstallForeignHook tests that hooks of other tools are left alone
+func TestInstallForeignHook(t *testing.T) {
+	hooksDir := t.TempDir()
+	path := filepath.Join(hooksDir, Name)
+	if err := os.WriteFile(path, []byte("#!/bin/sh\necho other\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Install(hooksDir, "kommit", false); !errors.Is(err, ErrForeignHook) {
+		t.Errorf("Install() error = %v, want %v", err, ErrForeignHook)
+	}
+	if _, err := Uninstall(hooksDir); !errors.Is(err, ErrForeignHook) {
+		t.Errorf("Uninstall() error = %v, want %v", err, ErrForeignHook)
+	}
+	if _, err := Install(hooksDir, "kommit", true); err != nil {
+		t.Errorf("Install() with force unexpected error: %v", err)
+	}
+}
+
+// TestShouldFill tests which commits get a generated message
+func TestShouldFill(t *testing.T) {
+	tests := []struct {
+		name     string
+		source   string
+		content  string
+		expected bool
+	}{
+		{name: "plain git commit", source: "", content: "", expected: true},
+		{name: "empty template", source: "template", content: "", expected: true},
+		{name: "filled template", source: "template", content: "JIRA-123: ", expected: false},
+		{name: "message option", source: "message", content: "Fix bug", expected: false},
+		{name: "merge", source: "merge", content: "Merge branch 'main'", expected: false},
+		{name: "squash", source: "squash", content: "", expected: false},
+		{name: "amend", source: "commit", content: "Old message", expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := ShouldFill(tt.source, tt.content); result != tt.expected {
+				t.Errorf("ShouldFill(%q, %q) = %v, want %v", tt.source, tt.content, result, tt.expected)
+			}
+		})
+	}
+}
+
+// TestFillMessage tests that the message is written in front of the comments
+func TestFillMessage(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "COMMIT_EDITMSG")
+	if err := os.WriteFile(path, []byte("\n# Please enter the commit message\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := FillMessage(path, "Add feature\n"); err != nil {
+		t.Fatalf("FillMessage() unexpected error: %v", err)
+	}
+
+	content, _ := os.ReadFile(path)
+	if !strings.HasPrefix(string(content), "Add feature\n\n# Please enter") {
+		t.Errorf("unexpected message file content: %q", content)
+	}
+}
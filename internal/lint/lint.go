@@ -0,0 +1,157 @@
+package lint
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/madflow/kommit/internal/config"
+	"github.com/madflow/kommit/internal/conventional"
+	"github.com/madflow/kommit/internal/git"
+)
+
+// Names of the checks
+const (
+	RuleEmpty          = "empty"
+	RuleSubjectLength  = "subject-length"
+	RuleBlankLine      = "blank-line"
+	RuleImperative     = "imperative"
+	RuleTrailingPeriod = "trailing-period"
+	RuleConventional   = "conventional"
+	RuleType           = "type"
+	RuleScope          = "scope"
+)
+
+// Violation is a failed check of a commit message
+type Violation struct {
+	Rule    string
+	Message string
+}
+
+// String returns the message of the violation with the name of the check
+func (v Violation) String() string {
+	return fmt.Sprintf("%s (%s)", v.Message, v.Rule)
+}
+
+// Check returns the violations of the commit message against the configured checks
+func Check(message string, rules config.LintConfig) []Violation {
+	message = strings.TrimSpace(message)
+	if message == "" {
+		return []Violation{{Rule: RuleEmpty, Message: "commit message is empty"}}
+	}
+
+	lines := strings.Split(message, "\n")
+	subject := strings.TrimSpace(lines[0])
+	// Messages written by git are not checked
+	if git.IsGeneratedSubject(subject) {
+		return nil
+	}
+
+	var violations []Violation
+	if length := utf8.RuneCountInString(subject); rules.MaxSubjectLength > 0 && length > rules.MaxSubjectLength {
+		violations = append(violations, Violation{
+			Rule:    RuleSubjectLength,
+			Message: fmt.Sprintf("subject has %d characters, at most %d are allowed", length, rules.MaxSubjectLength),
+		})
+	}
+	if rules.BlankLine && len(lines) > 1 && strings.TrimSpace(lines[1]) != "" {
+		violations = append(violations, Violation{Rule: RuleBlankLine, Message: "subject must be followed by a blank line"})
+	}
+	if rules.NoTrailingPeriod && strings.HasSuffix(subject, ".") && !strings.HasSuffix(subject, "...") {
+		violations = append(violations, Violation{Rule: RuleTrailingPeriod, Message: "subject must not end with a period"})
+	}
+
+	description := subject
+	header, isConventional := conventional.ParseHeader(subject)
+	if isConventional {
+		description = header.Description
+	}
+
+	if rules.Conventional {
+		if !isConventional {
+			violations = append(violations, Violation{Rule: RuleConventional, Message: "subject must have the format type(scope): description"})
+		} else {
+			if len(rules.Types) > 0 && !slices.Contains(rules.Types, header.Type) {
+				violations = append(violations, Violation{
+					Rule:    RuleType,
+					Message: fmt.Sprintf("type %q is not allowed, use one of %s", header.Type, strings.Join(rules.Types, ", ")),
+				})
+			}
+			if len(rules.Scopes) > 0 && header.Scope != "" && !slices.Contains(rules.Scopes, header.Scope) {
+				violations = append(violations, Violation{
+					Rule:    RuleScope,
+					Message: fmt.Sprintf("scope %q is not allowed, use one of %s", header.Scope, strings.Join(rules.Scopes, ", ")),
+				})
+			}
+		}
+	}
+
+	if rules.Imperative {
+		if word, ok := nonImperative(description); ok {
+			violations = append(violations, Violation{
+				Rule:    RuleImperative,
+				Message: fmt.Sprintf("subject must use the imperative mood (%q)", word),
+			})
+		}
+	}
+	return violations
+}
+
+// verbs are common verbs of commit messages, used to detect subjects that are not in the imperative mood
+var verbs = []string{
+	"add", "adapt", "adjust", "allow", "apply", "avoid", "build", "bump", "change", "clean",
+	"configure", "convert", "correct", "create", "delete", "deprecate", "disable", "document",
+	"drop", "enable", "ensure", "extract", "fix", "format", "generate", "handle", "implement",
+	"improve", "initialize", "introduce", "make", "merge", "migrate", "modify", "move",
+	"optimize", "prevent", "refactor", "release", "remove", "rename", "replace", "resolve",
+	"restore", "return", "revert", "rewrite", "set", "show", "simplify", "support", "test",
+	"update", "upgrade", "use", "validate", "write",
+}
+
+// irregular are past tense forms that do not follow the rules of inflect
+var irregular = []string{"built", "made", "set", "wrote", "rewrote", "written", "rewritten"}
+
+// nonImperativeForms is the set of non imperative forms of the verbs
+var nonImperativeForms = func() map[string]bool {
+	forms := make(map[string]bool)
+	for _, verb := range verbs {
+		for _, form := range inflect(verb) {
+			forms[form] = true
+		}
+	}
+	for _, form := range irregular {
+		forms[form] = true
+	}
+	// "set" is also the imperative
+	delete(forms, "set")
+	return forms
+}()
+
+// inflect returns the third person, past tense and gerund forms of a regular verb
+func inflect(verb string) []string {
+	forms := []string{verb + "s", verb + "es", verb + "ed", verb + "d", verb + "ing"}
+	last := verb[len(verb)-1]
+	switch {
+	case last == 'e':
+		forms = append(forms, verb[:len(verb)-1]+"ing")
+	case last == 'y':
+		stem := verb[:len(verb)-1]
+		forms = append(forms, stem+"ies", stem+"ied")
+	case !strings.ContainsRune("aeiouwxy", rune(last)) && strings.ContainsRune("aeiou", rune(verb[len(verb)-2])):
+		// Short verbs double the final consonant, e.g. dropped or setting
+		forms = append(forms, verb+string(last)+"ed", verb+string(last)+"ing")
+	}
+	return forms
+}
+
+// nonImperative returns the first word of the description if it is a common verb
+// that is not in the imperative mood, e.g. "Added" or "Fixes"
+func nonImperative(description string) (string, bool) {
+	fields := strings.Fields(description)
+	if len(fields) == 0 {
+		return "", false
+	}
+	word := strings.Trim(fields[0], ".,:;!")
+	return word, nonImperativeForms[strings.ToLower(word)]
+}
@@ -0,0 +1,115 @@
+package lint
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/madflow/kommit/internal/config"
+)
+
+// TestCheck tests the checks of commit messages
+func TestCheck(t *testing.T) {
+	defaults := config.DefaultConfig().Lint
+	conventionalRules := defaults
+	conventionalRules.Conventional = true
+	conventionalRules.Scopes = []string{"cli", "config"}
+
+	tests := []struct {
+		name     string
+		message  string
+		rules    config.LintConfig
+		expected []string
+	}{
+		{
+			name:    "valid message",
+			message: "Add lint command\n\nChecks messages against the configured rules.",
+			rules:   defaults,
+		},
+		{
+			name:     "empty message",
+			message:  "\n\n",
+			rules:    defaults,
+			expected: []string{RuleEmpty},
+		},
+		{
+			name:     "long subject",
+			message:  "Add a command that checks commit messages against all the rules configured in kommit",
+			rules:    defaults,
+			expected: []string{RuleSubjectLength},
+		},
+		{
+			name:     "length check disabled",
+			message:  "Add a command that checks commit messages against all the rules configured in kommit",
+			rules:    config.LintConfig{},
+			expected: nil,
+		},
+		{
+			name:     "missing blank line and trailing period",
+			message:  "Add lint command.\nChecks messages.",
+			rules:    defaults,
+			expected: []string{RuleBlankLine, RuleTrailingPeriod},
+		},
+		{
+			name:    "ellipsis is allowed",
+			message: "Add lint command...",
+			rules:   defaults,
+		},
+		{
+			name:     "past tense",
+			message:  "Added lint command",
+			rules:    defaults,
+			expected: []string{RuleImperative},
+		},
+		{
+			name:     "third person in conventional commit",
+			message:  "feat: fixes lint command",
+			rules:    defaults,
+			expected: []string{RuleImperative},
+		},
+		{
+			name:     "gerund with doubled consonant",
+			message:  "Dropping support for Go 1.22",
+			rules:    defaults,
+			expected: []string{RuleImperative},
+		},
+		{
+			name:    "set is imperative",
+			message: "Set default model",
+			rules:   defaults,
+		},
+		{
+			name:    "conventional commit",
+			message: "feat(cli): add lint command",
+			rules:   conventionalRules,
+		},
+		{
+			name:     "not conventional",
+			message:  "Add lint command",
+			rules:    conventionalRules,
+			expected: []string{RuleConventional},
+		},
+		{
+			name:     "type and scope not allowed",
+			message:  "feature(hook): add lint command",
+			rules:    conventionalRules,
+			expected: []string{RuleType, RuleScope},
+		},
+		{
+			name:    "generated by git",
+			message: "Merge branch 'main' into feature.",
+			rules:   conventionalRules,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var rules []string
+			for _, violation := range Check(tt.message, tt.rules) {
+				rules = append(rules, violation.Rule)
+			}
+			if !reflect.DeepEqual(rules, tt.expected) {
+				t.Errorf("Check() = %v, want %v", rules, tt.expected)
+			}
+		})
+	}
+}
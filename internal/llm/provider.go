@@ -0,0 +1,61 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/madflow/kommit/internal/config"
+)
+
+// Provider generates commit messages from staged changes using a language model backend
+type Provider interface {
+	GenerateCommitMessage(ctx context.Context, req *Request) (string, error)
+	// Complete sends a system and a user prompt to the model and returns the answer
+	Complete(ctx context.Context, system, user string) (string, error)
+}
+
+// Streamer is implemented by providers that can print tokens while the message is generated
+type Streamer interface {
+	SetStreamOutput(w io.Writer)
+}
+
+// Factory creates a Provider from the application configuration
+type Factory func(cfg *config.Config) (Provider, error)
+
+var factories = map[string]Factory{}
+
+// Register makes a provider available under the given name.
+// It panics if a provider with the same name is already registered.
+func Register(name string, factory Factory) {
+	if _, exists := factories[name]; exists {
+		panic(fmt.Sprintf("llm: provider %q registered twice", name))
+	}
+	factories[name] = factory
+}
+
+// Names returns the sorted names of all registered providers
+func Names() []string {
+	names := make([]string, 0, len(factories))
+	for name := range factories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// New returns the provider selected by the provider key of the configuration
+func New(cfg *config.Config) (Provider, error) {
+	name := cfg.Provider
+	if name == "" {
+		name = config.DefaultProvider
+	}
+
+	factory, ok := factories[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown provider %q (available: %s)", name, strings.Join(Names(), ", "))
+	}
+	return factory(cfg)
+}
@@ -0,0 +1,51 @@
+package llm
+
+import (
+	"context"
+	"testing"
+
+	"github.com/madflow/kommit/internal/config"
+)
+
+type fakeProvider struct{}
+
+func (fakeProvider) GenerateCommitMessage(ctx context.Context, req *Request) (string, error) {
+	return "fake", nil
+}
+
+func (fakeProvider) Complete(ctx context.Context, system, user string) (string, error) {
+	return "fake", nil
+}
+
+// TestNew tests provider selection by configuration
+func TestNew(t *testing.T) {
+	originalFactories := factories
+	defer func() { factories = originalFactories }()
+
+	factories = map[string]Factory{}
+	Register(config.DefaultProvider, func(cfg *config.Config) (Provider, error) {
+		return fakeProvider{}, nil
+	})
+
+	tests := []struct {
+		name     string
+		provider string
+		hasError bool
+	}{
+		{name: "empty falls back to default", provider: "", hasError: false},
+		{name: "registered provider", provider: config.DefaultProvider, hasError: false},
+		{name: "unknown provider", provider: "does-not-exist", hasError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := New(&config.Config{Provider: tt.provider})
+			if (err != nil) != tt.hasError {
+				t.Fatalf("New() error = %v, hasError %v", err, tt.hasError)
+			}
+			if !tt.hasError && p == nil {
+				t.Errorf("New() returned nil provider")
+			}
+		})
+	}
+}
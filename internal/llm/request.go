@@ -0,0 +1,58 @@
+package llm
+
+import (
+	"encoding/json"
+	"math/rand/v2"
+
+	"github.com/madflow/kommit/internal/git"
+)
+
+// Roles of the messages of a conversation
+const (
+	RoleSystem    = "system"
+	RoleUser      = "user"
+	RoleAssistant = "assistant"
+)
+
+// Message is a single turn of a conversation with the model
+type Message struct {
+	Role    string
+	Content string
+}
+
+// Request holds the input for generating a commit message
+type Request struct {
+	Diff    string
+	Rules   string
+	RepoCtx *git.RepoContext
+	// System and User are prompts rendered from templates of the configuration,
+	// they replace the built-in prompts if not empty
+	System string
+	User   string
+	// Examples holds recent commit messages of the repository showing its style
+	Examples []string
+	// Commits holds the messages of existing commits that are combined into one,
+	// e.g. when squashing a branch
+	Commits []string
+	// History holds the previous answers of the model and the feedback of the
+	// user when a message is refined
+	History []Message
+	// Format is the JSON schema of a structured answer, empty for a plain text message
+	Format  json.RawMessage
+	Options Options
+}
+
+// Options holds the sampling parameters of a request. Nil values use the defaults of the model.
+type Options struct {
+	Temperature *float64
+	Seed        *int
+}
+
+// CandidateOptions returns the sampling options for the n-th of several
+// generated messages. Every candidate gets a higher temperature and a new seed
+// so that the messages differ.
+func CandidateOptions(n int) Options {
+	temperature := min(0.4+0.2*float64(n), 1.2)
+	seed := rand.IntN(1 << 31)
+	return Options{Temperature: &temperature, Seed: &seed}
+}
@@ -2,29 +2,36 @@ package logger
 
 import (
 	"fmt"
+	"io"
 	"os"
 )
 
 // Logger provides methods for different log levels and formatted output
 type Logger struct {
-	// Add any configuration fields here if needed in the future
+	// out receives all output except warnings and errors
+	out io.Writer
 }
 
 // New creates a new Logger instance
 func New() *Logger {
-	return &Logger{}
+	return &Logger{out: os.Stdout}
+}
+
+// SetOutput sets the writer for all output except warnings and errors, which always go to stderr
+func (l *Logger) SetOutput(w io.Writer) {
+	l.out = w
 }
 
 // Info prints an informational message to stdout
 func (l *Logger) Info(format string, args ...any) {
 	msg := fmt.Sprintf(format, args...)
-	fmt.Printf("ℹ️ %s\n", msg)
+	fmt.Fprintf(l.out, "ℹ️ %s\n", msg)
 }
 
 // Success prints a success message to stdout
 func (l *Logger) Success(format string, args ...any) {
 	msg := fmt.Sprintf(format, args...)
-	fmt.Printf("✅ %s\n", msg)
+	fmt.Fprintf(l.out, "✅ %s\n", msg)
 }
 
 // Warning prints a warning message to stderr
@@ -52,17 +59,22 @@ func (l *Logger) Fatalf(format string, args ...any) {
 
 // Printf formats according to a format specifier and writes to stdout
 func (l *Logger) Printf(format string, args ...any) {
-	fmt.Printf(format, args...)
+	fmt.Fprintf(l.out, format, args...)
 }
 
 // Println formats using the default formats for its operands and writes to stdout
 func (l *Logger) Println(args ...any) {
-	fmt.Println(args...)
+	fmt.Fprintln(l.out, args...)
 }
 
 // Default logger instance for package-level functions
 var defaultLogger = New()
 
+// SetOutput sets the writer for all output except warnings and errors of the default logger
+func SetOutput(w io.Writer) {
+	defaultLogger.SetOutput(w)
+}
+
 // Info prints an informational message using the default logger
 func Info(format string, args ...any) {
 	defaultLogger.Info(format, args...)
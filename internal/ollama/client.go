@@ -2,100 +2,363 @@ package ollama
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/madflow/kommit/internal/config"
-	"github.com/madflow/kommit/internal/git"
+	"github.com/madflow/kommit/internal/llm"
+	"github.com/madflow/kommit/internal/prompt"
 )
 
+// API endpoints supported by the client
+const (
+	APIGenerate = "generate"
+	APIChat     = "chat"
+)
+
+var (
+	_ llm.Provider = (*Client)(nil)
+	_ llm.Streamer = (*Client)(nil)
+)
+
+func init() {
+	llm.Register("ollama", func(cfg *config.Config) (llm.Provider, error) {
+		client := NewClient(&cfg.Ollama)
+		client.ContextSize = cfg.Diff.ContextSize
+		return client, nil
+	})
+}
+
 // Client represents an Ollama API client
 type Client struct {
 	BaseURL string
 	Model   string
+	UseChat bool
+	Stream  bool
+	// Output receives the tokens as they arrive when streaming is enabled
+	Output io.Writer
+	// HTTPClient is used for all requests
+	HTTPClient *http.Client
+	// Retries is the number of times a transient failure is retried
+	Retries int
+	// RetryBackoff is the delay before the first retry, it doubles with every attempt
+	RetryBackoff time.Duration
+	// ContextSize sets the context window of the model (num_ctx) if greater than zero
+	ContextSize int
 }
 
 // Request represents a request to the Ollama API
 type Request struct {
 	Model  string `json:"model"`
+	System string `json:"system,omitempty"`
 	Prompt string `json:"prompt"`
 	Stream bool   `json:"stream"`
+	// Format constrains the answer to a JSON schema
+	Format  json.RawMessage `json:"format,omitempty"`
+	Options *Options        `json:"options,omitempty"`
+}
+
+// Options represents the model parameters of a request
+type Options struct {
+	NumCtx      int      `json:"num_ctx,omitempty"`
+	Temperature *float64 `json:"temperature,omitempty"`
+	Seed        *int     `json:"seed,omitempty"`
 }
 
 // Response represents a response from the Ollama API
 type Response struct {
 	Response string `json:"response"`
 	Done     bool   `json:"done"`
+	Error    string `json:"error,omitempty"`
+}
+
+// Message represents a single message of a chat request
+type Message struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// ChatRequest represents a request to the Ollama chat API
+type ChatRequest struct {
+	Model    string    `json:"model"`
+	Messages []Message `json:"messages"`
+	Stream   bool      `json:"stream"`
+	// Format constrains the answer to a JSON schema
+	Format  json.RawMessage `json:"format,omitempty"`
+	Options *Options        `json:"options,omitempty"`
+}
+
+// ChatResponse represents a response from the Ollama chat API
+type ChatResponse struct {
+	Message Message `json:"message"`
+	Done    bool    `json:"done"`
+	Error   string  `json:"error,omitempty"`
+}
+
+// APIError represents an error reported by the Ollama API
+type APIError struct {
+	StatusCode int
+	Message    string
+}
+
+// Error returns the error message reported by Ollama
+func (e *APIError) Error() string {
+	if e.StatusCode == 0 {
+		return fmt.Sprintf("ollama error: %s", e.Message)
+	}
+	if e.Message == "" {
+		return fmt.Sprintf("ollama error: %d %s", e.StatusCode, http.StatusText(e.StatusCode))
+	}
+	return fmt.Sprintf("ollama error (%d %s): %s", e.StatusCode, http.StatusText(e.StatusCode), e.Message)
+}
+
+// Temporary reports whether the request may succeed when retried
+func (e *APIError) Temporary() bool {
+	return e.StatusCode == http.StatusTooManyRequests || e.StatusCode >= http.StatusInternalServerError
 }
 
-// NewClient creates a new Ollama client with the given configuration
+// NewClient creates a new Ollama client with the given configuration.
+// The timeout limits the wait for the response headers of every attempt. Ollama
+// sends them once the whole answer is generated, or with the first token when
+// streaming, so a streamed answer is not cut off while the tokens arrive.
 func NewClient(cfg *config.OllamaConfig) *Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.ResponseHeaderTimeout = cfg.Timeout
+
 	return &Client{
 		BaseURL: cfg.ServerURL,
 		Model:   cfg.Model,
+		UseChat: cfg.API == APIChat,
+		Stream:  cfg.Stream,
+		HTTPClient: &http.Client{
+			Transport: transport,
+		},
+		Retries:      cfg.Retries,
+		RetryBackoff: cfg.RetryBackoff,
 	}
 }
 
+// SetStreamOutput sets the writer that receives the tokens while streaming
+func (c *Client) SetStreamOutput(w io.Writer) {
+	c.Output = w
+}
+
 // GenerateCommitMessage generates a commit message using the Ollama API
-func (c *Client) GenerateCommitMessage(diff, rules string, repoCtx *git.RepoContext) (string, error) {
-	// Truncate diff if it's too long (Ollama has token limits)
-	maxDiffLength := 4000
-	if len(diff) > maxDiffLength {
-		diff = diff[:maxDiffLength] + "\n... (truncated)"
-	}
-
-	// Build the prompt using the rules and repository context
-	prompt := fmt.Sprintf(`
-You are a git commit message generator. 
-Output ONLY the commit message in plain text format with no additional text, headers, or formatting.
-
-Repository Context:
-- Branch: %s
-- Files changed: %d
-- Changed files:%s
-
-IMPORTANT Rules:
-%s
-
-Git diff:
-%s`,
-		repoCtx.BranchName,
-		repoCtx.FilesChanged,
-		func() string {
-			if len(repoCtx.FileChanges) == 0 {
-				return " (none)"
-			}
-			var files []string
-			for _, change := range repoCtx.FileChanges {
-				files = append(files, fmt.Sprintf("\n  - [%s] %s (%s)", change.Status, change.FilePath, change.FileType))
-			}
-			return strings.Join(files, "")
-		}(),
-		rules,
-		diff)
-
-	reqBody, err := json.Marshal(Request{
-		Model:  c.Model,
-		Prompt: prompt,
-		Stream: false,
+func (c *Client) GenerateCommitMessage(ctx context.Context, req *llm.Request) (string, error) {
+	// The chat API gets the rules as system message and the repository context plus diff as user message
+	system, user := prompt.Messages(req)
+	if c.UseChat {
+		messages := []Message{
+			{Role: llm.RoleSystem, Content: system},
+			{Role: llm.RoleUser, Content: user},
+		}
+		for _, message := range req.History {
+			messages = append(messages, Message{Role: message.Role, Content: message.Content})
+		}
+		return c.chat(ctx, messages, req.Format, req.Options, c.Stream)
+	}
+	// Prompts from templates use the system field of the generate API
+	if req.System != "" || req.User != "" {
+		return c.generate(ctx, system, user+prompt.Transcript(req.History), req.Format, req.Options, c.Stream)
+	}
+	userPrompt := prompt.Build(req.Diff, req.Rules, req.RepoCtx) + prompt.Examples(req.Examples) + prompt.Commits(req.Commits) + prompt.Structured(req.Format) + prompt.Transcript(req.History)
+	return c.generate(ctx, "", userPrompt, req.Format, req.Options, c.Stream)
+}
+
+// Complete sends a system and a user prompt to the model and returns the answer.
+// The answer is never streamed so that concurrent requests do not interleave.
+func (c *Client) Complete(ctx context.Context, system, user string) (string, error) {
+	if c.UseChat {
+		return c.chat(ctx, []Message{
+			{Role: llm.RoleSystem, Content: system},
+			{Role: llm.RoleUser, Content: user},
+		}, nil, llm.Options{}, false)
+	}
+	return c.generate(ctx, system, user, nil, llm.Options{}, false)
+}
+
+// generate sends a single prompt to the /api/generate endpoint, the answer is
+// constrained to the JSON schema of the format if it is not empty
+func (c *Client) generate(ctx context.Context, system, userPrompt string, format json.RawMessage, opts llm.Options, stream bool) (string, error) {
+	body, err := c.post(ctx, c.BaseURL, Request{
+		Model:   c.Model,
+		System:  system,
+		Prompt:  userPrompt,
+		Stream:  stream,
+		Format:  format,
+		Options: c.options(opts),
+	})
+	if err != nil {
+		return "", err
+	}
+	defer body.Close()
+
+	return c.readResponse(body, stream, func(dec *json.Decoder) (string, bool, error) {
+		var ollamaResp Response
+		err := dec.Decode(&ollamaResp)
+		if err == nil && ollamaResp.Error != "" {
+			err = &APIError{Message: ollamaResp.Error}
+		}
+		return ollamaResp.Response, ollamaResp.Done, err
 	})
+}
+
+// chat sends the messages to the /api/chat endpoint, the answer is constrained
+// to the JSON schema of the format if it is not empty
+func (c *Client) chat(ctx context.Context, messages []Message, format json.RawMessage, opts llm.Options, stream bool) (string, error) {
+	body, err := c.post(ctx, c.ChatURL(), ChatRequest{
+		Model:    c.Model,
+		Messages: messages,
+		Stream:   stream,
+		Format:   format,
+		Options:  c.options(opts),
+	})
+	if err != nil {
+		return "", err
+	}
+	defer body.Close()
+
+	return c.readResponse(body, stream, func(dec *json.Decoder) (string, bool, error) {
+		var chatResp ChatResponse
+		err := dec.Decode(&chatResp)
+		if err == nil && chatResp.Error != "" {
+			err = &APIError{Message: chatResp.Error}
+		}
+		return chatResp.Message.Content, chatResp.Done, err
+	})
+}
+
+// options returns the model parameters of a request
+func (c *Client) options(opts llm.Options) *Options {
+	if c.ContextSize <= 0 && opts.Temperature == nil && opts.Seed == nil {
+		return nil
+	}
+	return &Options{
+		NumCtx:      max(c.ContextSize, 0),
+		Temperature: opts.Temperature,
+		Seed:        opts.Seed,
+	}
+}
+
+// ChatURL returns the URL of the chat endpoint derived from the configured server URL
+func (c *Client) ChatURL() string {
+	url := strings.TrimRight(c.BaseURL, "/")
+	if strings.HasSuffix(url, "/api/"+APIChat) {
+		return url
+	}
+	return strings.TrimSuffix(url, "/api/"+APIGenerate) + "/api/" + APIChat
+}
+
+// post sends the request body as JSON to the given URL and returns the response body.
+// Transient failures (network errors, 429 and 5xx responses) are retried with
+// exponential backoff.
+func (c *Client) post(ctx context.Context, url string, body any) (io.ReadCloser, error) {
+	reqBody, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("error creating request: %w", err)
+	}
+
+	backoff := c.RetryBackoff
+	for attempt := 0; ; attempt++ {
+		resp, err := c.do(ctx, url, reqBody)
+		if err == nil {
+			return resp.Body, nil
+		}
+		if attempt >= c.Retries || !isTransient(ctx, err) {
+			return nil, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+// do performs a single request attempt. Non-200 responses are returned as *APIError.
+func (c *Client) do(ctx context.Context, url string, reqBody []byte) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(reqBody))
 	if err != nil {
-		return "", fmt.Errorf("error creating request: %v", err)
+		return nil, fmt.Errorf("error creating request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	httpClient := c.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
 	}
 
-	// Make the request
-	resp, err := http.Post(c.BaseURL, "application/json", bytes.NewBuffer(reqBody))
+	resp, err := httpClient.Do(req)
 	if err != nil {
-		return "", fmt.Errorf("error making request to Ollama: %v", err)
+		return nil, fmt.Errorf("error making request to Ollama: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		apiErr := &APIError{StatusCode: resp.StatusCode}
+		var errResp struct {
+			Error string `json:"error"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&errResp); err == nil {
+			apiErr.Message = errResp.Error
+		}
+		return nil, apiErr
 	}
-	defer resp.Body.Close()
+	return resp, nil
+}
 
-	var ollamaResp Response
-	if err := json.NewDecoder(resp.Body).Decode(&ollamaResp); err != nil {
-		return "", fmt.Errorf("error decoding response: %v", err)
+// isTransient reports whether a failed request attempt should be retried
+func isTransient(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.Temporary()
 	}
+	return true
+}
+
+// readResponse decodes the newline delimited JSON objects of a (streamed) response
+// until the final object and assembles the message. When streaming, each token is
+// written to the output as it arrives.
+func (c *Client) readResponse(r io.Reader, stream bool, next func(dec *json.Decoder) (string, bool, error)) (string, error) {
+	streaming := stream && c.Output != nil
+	dec := json.NewDecoder(r)
 
-	return ollamaResp.Response, nil
+	var message strings.Builder
+	for {
+		token, done, err := next(dec)
+		if err == io.EOF && message.Len() > 0 {
+			break
+		}
+		var apiErr *APIError
+		if errors.As(err, &apiErr) {
+			return "", err
+		}
+		if err != nil {
+			return "", fmt.Errorf("error decoding response: %w", err)
+		}
+
+		message.WriteString(token)
+		if streaming {
+			fmt.Fprint(c.Output, token)
+		}
+		if done {
+			break
+		}
+	}
+
+	if streaming {
+		fmt.Fprintln(c.Output)
+	}
+	return message.String(), nil
 }
@@ -0,0 +1,356 @@
+package ollama
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/madflow/kommit/internal/config"
+	"github.com/madflow/kommit/internal/git"
+	"github.com/madflow/kommit/internal/llm"
+)
+
+// TestChatURL tests deriving the chat endpoint from the configured server URL
+func TestChatURL(t *testing.T) {
+	tests := []struct {
+		name      string
+		serverURL string
+		expected  string
+	}{
+		{name: "generate endpoint", serverURL: "http://localhost:11434/api/generate", expected: "http://localhost:11434/api/chat"},
+		{name: "chat endpoint", serverURL: "http://localhost:11434/api/chat", expected: "http://localhost:11434/api/chat"},
+		{name: "base url", serverURL: "http://localhost:11434/", expected: "http://localhost:11434/api/chat"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := NewClient(&config.OllamaConfig{ServerURL: tt.serverURL})
+			if result := client.ChatURL(); result != tt.expected {
+				t.Errorf("ChatURL() = %q, want %q", result, tt.expected)
+			}
+		})
+	}
+}
+
+// TestGenerateCommitMessage tests both the generate and the chat endpoint
+func TestGenerateCommitMessage(t *testing.T) {
+	repoCtx := &git.RepoContext{BranchName: "main", FilesChanged: 1}
+
+	tests := []struct {
+		name string
+		api  string
+		path string
+		body string
+	}{
+		{name: "generate api", api: APIGenerate, path: "/api/generate", body: `{"response":"Add feature","done":true}`},
+		{name: "chat api", api: APIChat, path: "/api/chat", body: `{"message":{"role":"assistant","content":"Add feature"},"done":true}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != tt.path {
+					t.Errorf("request path = %q, want %q", r.URL.Path, tt.path)
+				}
+				if tt.api == APIChat {
+					var req ChatRequest
+					if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+						t.Fatalf("error decoding request: %v", err)
+					}
+					if len(req.Messages) != 2 || req.Messages[0].Role != "system" || req.Messages[1].Role != "user" {
+						t.Errorf("unexpected messages: %+v", req.Messages)
+					}
+				}
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			client := NewClient(&config.OllamaConfig{ServerURL: server.URL + "/api/generate", Model: "test", API: tt.api})
+			result, err := client.GenerateCommitMessage(context.Background(), &llm.Request{Diff: "diff", Rules: "rules", RepoCtx: repoCtx})
+			if err != nil {
+				t.Fatalf("GenerateCommitMessage() unexpected error: %v", err)
+			}
+			if result != "Add feature" {
+				t.Errorf("GenerateCommitMessage() = %q, want %q", result, "Add feature")
+			}
+		})
+	}
+}
+
+// TestGenerateCommitMessageStream tests decoding of streamed NDJSON chunks
+func TestGenerateCommitMessageStream(t *testing.T) {
+	tests := []struct {
+		name string
+		api  string
+		body string
+	}{
+		{
+			name: "generate api",
+			api:  APIGenerate,
+			body: "{\"response\":\"Add \",\"done\":false}\n{\"response\":\"streaming\",\"done\":false}\n{\"response\":\"\",\"done\":true}\n",
+		},
+		{
+			name: "chat api",
+			api:  APIChat,
+			body: "{\"message\":{\"role\":\"assistant\",\"content\":\"Add \"},\"done\":false}\n{\"message\":{\"role\":\"assistant\",\"content\":\"streaming\"},\"done\":true}\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var req struct {
+					Stream bool `json:"stream"`
+				}
+				if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+					t.Fatalf("error decoding request: %v", err)
+				}
+				if !req.Stream {
+					t.Errorf("request stream = false, want true")
+				}
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			var output bytes.Buffer
+			client := NewClient(&config.OllamaConfig{ServerURL: server.URL, Model: "test", API: tt.api, Stream: true})
+			client.SetStreamOutput(&output)
+
+			result, err := client.GenerateCommitMessage(context.Background(), &llm.Request{Diff: "diff", Rules: "rules", RepoCtx: &git.RepoContext{}})
+			if err != nil {
+				t.Fatalf("GenerateCommitMessage() unexpected error: %v", err)
+			}
+			if result != "Add streaming" {
+				t.Errorf("GenerateCommitMessage() = %q, want %q", result, "Add streaming")
+			}
+			if output.String() != "Add streaming\n" {
+				t.Errorf("stream output = %q, want %q", output.String(), "Add streaming\n")
+			}
+		})
+	}
+}
+
+// TestGenerateCommitMessageErrors tests retries and surfacing of Ollama error responses
+func TestGenerateCommitMessageErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		statuses []int
+		body     string
+		retries  int
+		attempts int
+		errText  string
+	}{
+		{
+			name:     "retries transient failures",
+			statuses: []int{http.StatusServiceUnavailable, http.StatusOK},
+			retries:  2,
+			attempts: 2,
+		},
+		{
+			name:     "gives up after retries",
+			statuses: []int{http.StatusBadGateway, http.StatusBadGateway, http.StatusBadGateway},
+			retries:  2,
+			attempts: 3,
+			errText:  "502",
+		},
+		{
+			name:     "surfaces error body without retry",
+			statuses: []int{http.StatusNotFound},
+			body:     `{"error":"model \"missing\" not found, try pulling it first"}`,
+			retries:  2,
+			attempts: 1,
+			errText:  `model "missing" not found`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			attempts := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				status := tt.statuses[attempts]
+				attempts++
+				w.WriteHeader(status)
+				if status == http.StatusOK {
+					_, _ = w.Write([]byte(`{"response":"Add feature","done":true}`))
+					return
+				}
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			client := NewClient(&config.OllamaConfig{ServerURL: server.URL, Model: "test", Retries: tt.retries, RetryBackoff: time.Millisecond})
+			result, err := client.GenerateCommitMessage(context.Background(), &llm.Request{Diff: "diff", Rules: "rules", RepoCtx: &git.RepoContext{}})
+
+			if attempts != tt.attempts {
+				t.Errorf("attempts = %d, want %d", attempts, tt.attempts)
+			}
+			if tt.errText != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.errText) {
+					t.Fatalf("GenerateCommitMessage() error = %v, want error containing %q", err, tt.errText)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("GenerateCommitMessage() unexpected error: %v", err)
+			}
+			if result != "Add feature" {
+				t.Errorf("GenerateCommitMessage() = %q, want %q", result, "Add feature")
+			}
+		})
+	}
+}
+
+// TestGenerateCommitMessageCancel tests that a cancelled context aborts the request
+func TestGenerateCommitMessageCancel(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	client := NewClient(&config.OllamaConfig{ServerURL: server.URL, Model: "test", Retries: 3, RetryBackoff: time.Millisecond})
+	_, err := client.GenerateCommitMessage(ctx, &llm.Request{Diff: "diff", Rules: "rules", RepoCtx: &git.RepoContext{}})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("GenerateCommitMessage() error = %v, want %v", err, context.Canceled)
+	}
+}
+
+// TestGenerateCommitMessageHistory tests that refinement turns are sent to both endpoints
+func TestGenerateCommitMessageHistory(t *testing.T) {
+	history := []llm.Message{
+		{Role: llm.RoleAssistant, Content: "Add feature"},
+		{Role: llm.RoleUser, Content: "Make it shorter"},
+	}
+
+	tests := []struct {
+		name string
+		api  string
+		body string
+	}{
+		{name: "generate api", api: APIGenerate, body: `{"response":"Add X","done":true}`},
+		{name: "chat api", api: APIChat, body: `{"message":{"role":"assistant","content":"Add X"},"done":true}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var req struct {
+					Prompt   string    `json:"prompt"`
+					Messages []Message `json:"messages"`
+				}
+				if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+					t.Fatalf("error decoding request: %v", err)
+				}
+				if tt.api == APIChat {
+					if len(req.Messages) != 4 || req.Messages[2].Role != llm.RoleAssistant || req.Messages[3].Content != "Make it shorter" {
+						t.Errorf("unexpected messages: %+v", req.Messages)
+					}
+				} else if !strings.HasSuffix(req.Prompt, "Your previous commit message:\nAdd feature\n\nMake it shorter") {
+					t.Errorf("prompt does not end with the transcript: %q", req.Prompt)
+				}
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			client := NewClient(&config.OllamaConfig{ServerURL: server.URL + "/api/generate", Model: "test", API: tt.api})
+			result, err := client.GenerateCommitMessage(context.Background(), &llm.Request{RepoCtx: &git.RepoContext{}, History: history})
+			if err != nil {
+				t.Fatalf("GenerateCommitMessage() unexpected error: %v", err)
+			}
+			if result != "Add X" {
+				t.Errorf("GenerateCommitMessage() = %q, want %q", result, "Add X")
+			}
+		})
+	}
+}
+
+// TestGenerateCommitMessageFormat tests sending the JSON schema of a structured answer
+func TestGenerateCommitMessageFormat(t *testing.T) {
+	format := json.RawMessage(`{"type":"object"}`)
+
+	tests := []struct {
+		name string
+		api  string
+		body string
+	}{
+		{name: "generate api", api: APIGenerate, body: `{"response":"{}","done":true}`},
+		{name: "chat api", api: APIChat, body: `{"message":{"role":"assistant","content":"{}"},"done":true}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var req struct {
+					Format json.RawMessage `json:"format"`
+				}
+				if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+					t.Fatalf("error decoding request: %v", err)
+				}
+				if string(req.Format) != string(format) {
+					t.Errorf("format = %s, want %s", req.Format, format)
+				}
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			client := NewClient(&config.OllamaConfig{ServerURL: server.URL + "/api/generate", Model: "test", API: tt.api})
+			if _, err := client.GenerateCommitMessage(context.Background(), &llm.Request{RepoCtx: &git.RepoContext{}, Format: format}); err != nil {
+				t.Fatalf("GenerateCommitMessage() unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+// TestGenerateCommitMessageTimeout tests that the timeout only limits the wait for
+// the response and not a streamed answer that takes longer
+func TestGenerateCommitMessageTimeout(t *testing.T) {
+	tests := []struct {
+		name        string
+		stream      bool
+		headerDelay time.Duration
+		tokenDelay  time.Duration
+		wantErr     bool
+	}{
+		{name: "slow stream", stream: true, tokenDelay: 100 * time.Millisecond},
+		{name: "no response", headerDelay: 300 * time.Millisecond, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				time.Sleep(tt.headerDelay)
+				w.WriteHeader(http.StatusOK)
+				for _, token := range []string{"Add", " slow", " feature"} {
+					_, _ = w.Write([]byte(`{"response":"` + token + `","done":false}` + "\n"))
+					w.(http.Flusher).Flush()
+					time.Sleep(tt.tokenDelay)
+				}
+				_, _ = w.Write([]byte(`{"response":"","done":true}` + "\n"))
+			}))
+			defer server.Close()
+
+			client := NewClient(&config.OllamaConfig{ServerURL: server.URL, Model: "test", Stream: tt.stream, Timeout: 150 * time.Millisecond})
+			result, err := client.GenerateCommitMessage(context.Background(), &llm.Request{RepoCtx: &git.RepoContext{}})
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("GenerateCommitMessage() = %q, want timeout error", result)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("GenerateCommitMessage() unexpected error: %v", err)
+			}
+			if result != "Add slow feature" {
+				t.Errorf("GenerateCommitMessage() = %q, want %q", result, "Add slow feature")
+			}
+		})
+	}
+}
@@ -0,0 +1,170 @@
+package openai
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+
+	"github.com/madflow/kommit/internal/config"
+	"github.com/madflow/kommit/internal/llm"
+	"github.com/madflow/kommit/internal/prompt"
+)
+
+// APIKeyEnv is the environment variable used when no API key is configured
+const APIKeyEnv = "OPENAI_API_KEY"
+
+var _ llm.Provider = (*Client)(nil)
+
+func init() {
+	llm.Register("openai", func(cfg *config.Config) (llm.Provider, error) {
+		return NewClient(&cfg.OpenAI), nil
+	})
+}
+
+// Client represents a client for OpenAI compatible chat completions APIs
+// (OpenAI, LM Studio, vLLM, llama.cpp server, ...)
+type Client struct {
+	BaseURL string
+	Model   string
+	APIKey  string
+}
+
+// Message represents a single chat message
+type Message struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// Request represents a request to the chat completions API
+type Request struct {
+	Model       string    `json:"model"`
+	Messages    []Message `json:"messages"`
+	Stream      bool      `json:"stream"`
+	Temperature *float64  `json:"temperature,omitempty"`
+	Seed        *int      `json:"seed,omitempty"`
+	// ResponseFormat constrains the answer to a JSON schema
+	ResponseFormat *ResponseFormat `json:"response_format,omitempty"`
+}
+
+// ResponseFormat represents the structured output settings of a request
+type ResponseFormat struct {
+	Type       string     `json:"type"`
+	JSONSchema JSONSchema `json:"json_schema"`
+}
+
+// JSONSchema represents a named JSON schema of a structured answer
+type JSONSchema struct {
+	Name   string          `json:"name"`
+	Strict bool            `json:"strict"`
+	Schema json.RawMessage `json:"schema"`
+}
+
+// Response represents a response from the chat completions API
+type Response struct {
+	Choices []Choice `json:"choices"`
+}
+
+// Choice represents a single completion choice
+type Choice struct {
+	Message Message `json:"message"`
+}
+
+// ErrorResponse represents an error returned by the chat completions API
+type ErrorResponse struct {
+	Error struct {
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// NewClient creates a new chat completions client with the given configuration.
+// The API key falls back to the OPENAI_API_KEY environment variable.
+func NewClient(cfg *config.OpenAIConfig) *Client {
+	apiKey := cfg.APIKey
+	if apiKey == "" {
+		apiKey = os.Getenv(APIKeyEnv)
+	}
+	return &Client{
+		BaseURL: cfg.ServerURL,
+		Model:   cfg.Model,
+		APIKey:  apiKey,
+	}
+}
+
+// GenerateCommitMessage generates a commit message using the chat completions API
+func (c *Client) GenerateCommitMessage(ctx context.Context, req *llm.Request) (string, error) {
+	system, user := prompt.Messages(req)
+	messages := []Message{
+		{Role: llm.RoleSystem, Content: system},
+		{Role: llm.RoleUser, Content: user},
+	}
+	for _, message := range req.History {
+		messages = append(messages, Message{Role: message.Role, Content: message.Content})
+	}
+	return c.chat(ctx, messages, req.Format, req.Options)
+}
+
+// Complete sends a system and a user message to the chat completions API and returns the answer
+func (c *Client) Complete(ctx context.Context, system, user string) (string, error) {
+	return c.chat(ctx, []Message{
+		{Role: llm.RoleSystem, Content: system},
+		{Role: llm.RoleUser, Content: user},
+	}, nil, llm.Options{})
+}
+
+// chat sends the messages to the chat completions API and returns the answer. The
+// answer is constrained to the JSON schema of the format if it is not empty.
+func (c *Client) chat(ctx context.Context, messages []Message, format json.RawMessage, opts llm.Options) (string, error) {
+	chatReq := Request{
+		Model:       c.Model,
+		Messages:    messages,
+		Stream:      false,
+		Temperature: opts.Temperature,
+		Seed:        opts.Seed,
+	}
+	if len(format) > 0 {
+		chatReq.ResponseFormat = &ResponseFormat{
+			Type:       "json_schema",
+			JSONSchema: JSONSchema{Name: "commit_message", Strict: true, Schema: format},
+		}
+	}
+	reqBody, err := json.Marshal(chatReq)
+	if err != nil {
+		return "", fmt.Errorf("error creating request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.BaseURL, bytes.NewBuffer(reqBody))
+	if err != nil {
+		return "", fmt.Errorf("error creating request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+c.APIKey)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("error making request to %s: %w", c.BaseURL, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		var errResp ErrorResponse
+		if err := json.NewDecoder(resp.Body).Decode(&errResp); err == nil && errResp.Error.Message != "" {
+			return "", fmt.Errorf("chat completions request failed (%s): %s", resp.Status, errResp.Error.Message)
+		}
+		return "", fmt.Errorf("chat completions request failed: %s", resp.Status)
+	}
+
+	var chatResp Response
+	if err := json.NewDecoder(resp.Body).Decode(&chatResp); err != nil {
+		return "", fmt.Errorf("error decoding response: %w", err)
+	}
+	if len(chatResp.Choices) == 0 {
+		return "", fmt.Errorf("chat completions response contained no choices")
+	}
+
+	return chatResp.Choices[0].Message.Content, nil
+}
@@ -0,0 +1,141 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/madflow/kommit/internal/config"
+	"github.com/madflow/kommit/internal/git"
+	"github.com/madflow/kommit/internal/llm"
+)
+
+// TestGenerateCommitMessage tests the chat completions client against a local test server
+func TestGenerateCommitMessage(t *testing.T) {
+	repoCtx := &git.RepoContext{
+		BranchName:   "main",
+		FilesChanged: 1,
+		FileChanges:  []git.FileChange{{Status: "M", FilePath: "main.go", FileType: "go"}},
+	}
+
+	tests := []struct {
+		name     string
+		apiKey   string
+		status   int
+		body     string
+		expected string
+		errText  string
+	}{
+		{
+			name:     "successful completion",
+			apiKey:   "secret",
+			status:   http.StatusOK,
+			body:     `{"choices":[{"message":{"role":"assistant","content":"Add main entrypoint"}}]}`,
+			expected: "Add main entrypoint",
+		},
+		{
+			name:     "without api key",
+			status:   http.StatusOK,
+			body:     `{"choices":[{"message":{"role":"assistant","content":"Fix typo"}}]}`,
+			expected: "Fix typo",
+		},
+		{
+			name:    "error body is surfaced",
+			status:  http.StatusNotFound,
+			body:    `{"error":{"message":"model not found"}}`,
+			errText: "model not found",
+		},
+		{
+			name:    "empty choices",
+			status:  http.StatusOK,
+			body:    `{"choices":[]}`,
+			errText: "no choices",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				wantAuth := ""
+				if tt.apiKey != "" {
+					wantAuth = "Bearer " + tt.apiKey
+				}
+				if got := r.Header.Get("Authorization"); got != wantAuth {
+					t.Errorf("Authorization header = %q, want %q", got, wantAuth)
+				}
+
+				var req Request
+				if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+					t.Fatalf("error decoding request: %v", err)
+				}
+				if len(req.Messages) != 2 || req.Messages[0].Role != "system" || req.Messages[1].Role != "user" {
+					t.Errorf("unexpected messages: %+v", req.Messages)
+				}
+				if !strings.Contains(req.Messages[1].Content, "main.go") {
+					t.Errorf("user message does not contain the changed files: %q", req.Messages[1].Content)
+				}
+
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			t.Setenv(APIKeyEnv, "")
+			client := NewClient(&config.OpenAIConfig{ServerURL: server.URL, Model: "test", APIKey: tt.apiKey})
+
+			result, err := client.GenerateCommitMessage(context.Background(), &llm.Request{Diff: "diff --git a/main.go b/main.go", Rules: "rules", RepoCtx: repoCtx})
+			if tt.errText != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.errText) {
+					t.Fatalf("GenerateCommitMessage() error = %v, want error containing %q", err, tt.errText)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("GenerateCommitMessage() unexpected error: %v", err)
+			}
+			if result != tt.expected {
+				t.Errorf("GenerateCommitMessage() = %q, want %q", result, tt.expected)
+			}
+		})
+	}
+}
+
+// TestNewClientAPIKeyFromEnv tests the API key fallback to the environment
+func TestNewClientAPIKeyFromEnv(t *testing.T) {
+	t.Setenv(APIKeyEnv, "from-env")
+
+	client := NewClient(&config.OpenAIConfig{})
+	if client.APIKey != "from-env" {
+		t.Errorf("APIKey = %q, want %q", client.APIKey, "from-env")
+	}
+
+	client = NewClient(&config.OpenAIConfig{APIKey: "from-config"})
+	if client.APIKey != "from-config" {
+		t.Errorf("APIKey = %q, want %q", client.APIKey, "from-config")
+	}
+}
+
+// TestGenerateCommitMessageFormat tests requesting a structured answer with a JSON schema
+func TestGenerateCommitMessageFormat(t *testing.T) {
+	format := json.RawMessage(`{"type":"object"}`)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req Request
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatalf("error decoding request: %v", err)
+		}
+		if req.ResponseFormat == nil || req.ResponseFormat.Type != "json_schema" || string(req.ResponseFormat.JSONSchema.Schema) != string(format) {
+			t.Errorf("unexpected response format: %+v", req.ResponseFormat)
+		}
+		_, _ = w.Write([]byte(`{"choices":[{"message":{"role":"assistant","content":"{}"}}]}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(&config.OpenAIConfig{ServerURL: server.URL, Model: "test"})
+	if _, err := client.GenerateCommitMessage(context.Background(), &llm.Request{RepoCtx: &git.RepoContext{}, Format: format}); err != nil {
+		t.Fatalf("GenerateCommitMessage() unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,53 @@
+package pr
+
+import "strings"
+
+// Description is the title and the markdown body of a pull request
+type Description struct {
+	Title string
+	Body  string
+}
+
+// String returns the title and the body separated by a blank line
+func (d Description) String() string {
+	if d.Body == "" {
+		return d.Title
+	}
+	return d.Title + "\n\n" + d.Body
+}
+
+// Parse splits the answer of the model into the title on the first line and the
+// body. Code fences around the answer and labels like "Title:" are removed.
+func Parse(answer string) Description {
+	answer = strings.TrimSpace(answer)
+	if strings.HasPrefix(answer, "```") && strings.HasSuffix(answer, "```") {
+		answer = strings.TrimSuffix(answer, "```")
+		if _, rest, ok := strings.Cut(answer, "\n"); ok {
+			answer = rest
+		}
+		answer = strings.TrimSpace(answer)
+	}
+
+	title, body, _ := strings.Cut(answer, "\n")
+	title = strings.TrimSpace(strings.TrimLeft(title, "# "))
+	title = trimLabel(title, "title:")
+	title = strings.Trim(title, `*"`)
+
+	body = strings.TrimSpace(body)
+	for _, label := range []string{"description:", "body:"} {
+		body = trimLabel(body, label)
+	}
+
+	return Description{
+		Title: strings.TrimSpace(title),
+		Body:  strings.TrimSpace(body),
+	}
+}
+
+// trimLabel removes a case insensitive label from the start of the text
+func trimLabel(text, label string) string {
+	if len(text) >= len(label) && strings.EqualFold(text[:len(label)], label) {
+		return strings.TrimSpace(text[len(label):])
+	}
+	return text
+}
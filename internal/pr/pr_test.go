@@ -0,0 +1,41 @@
+package pr
+
+import "testing"
+
+// TestParse tests splitting the answer of the model into title and body
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name     string
+		answer   string
+		expected Description
+	}{
+		{
+			name:     "title and body",
+			answer:   "Add pull request command\n\nAdds `kommit pr`.\n\n## Changes\n- New command",
+			expected: Description{Title: "Add pull request command", Body: "Adds `kommit pr`.\n\n## Changes\n- New command"},
+		},
+		{
+			name:     "title only",
+			answer:   "  Fix typo\n",
+			expected: Description{Title: "Fix typo"},
+		},
+		{
+			name:     "labels and heading",
+			answer:   "# Title: Add pull request command\n\nDescription:\nAdds a command.",
+			expected: Description{Title: "Add pull request command", Body: "Adds a command."},
+		},
+		{
+			name:     "code fence",
+			answer:   "```markdown\nAdd pull request command\n\nAdds a command.\n```",
+			expected: Description{Title: "Add pull request command", Body: "Adds a command."},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := Parse(tt.answer); result != tt.expected {
+				t.Errorf("Parse() = %+v, want %+v", result, tt.expected)
+			}
+		})
+	}
+}
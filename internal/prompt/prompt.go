@@ -0,0 +1,245 @@
+package prompt
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/madflow/kommit/internal/diff"
+	"github.com/madflow/kommit/internal/git"
+	"github.com/madflow/kommit/internal/llm"
+)
+
+// DiffBudget returns the number of tokens left for the diff once the response
+// and the remaining prompt of the request have been subtracted from the model context size
+func DiffBudget(contextSize, responseTokens int, req *llm.Request) int {
+	rest := Build("", req.Rules, req.RepoCtx) + Examples(req.Examples) + Commits(req.Commits) + Structured(req.Format)
+	if req.System != "" || req.User != "" {
+		withoutDiff := *req
+		withoutDiff.Diff = ""
+		system, user := Messages(&withoutDiff)
+		rest = system + user
+	}
+	return contextSize - responseTokens - diff.EstimateTokens(rest)
+}
+
+// Build returns the single prompt used by completion style endpoints
+func Build(diff, rules string, repoCtx *git.RepoContext) string {
+	return fmt.Sprintf(`
+You are a git commit message generator. 
+Output ONLY the commit message in plain text format with no additional text, headers, or formatting.
+
+Repository Context:
+- Branch: %s
+- Files changed: %d
+- Changed files:%s
+
+IMPORTANT Rules:
+%s
+
+Git diff:
+%s`,
+		repoCtx.BranchName,
+		repoCtx.FilesChanged,
+		changedFiles(repoCtx),
+		rules,
+		diff)
+}
+
+// System returns the system message used by chat style endpoints
+func System(rules string) string {
+	return fmt.Sprintf(`You are a git commit message generator.
+Output ONLY the commit message in plain text format with no additional text, headers, or formatting.
+
+IMPORTANT Rules:
+%s`, rules)
+}
+
+// User returns the user message with the repository context and the diff used by chat style endpoints
+func User(diff string, repoCtx *git.RepoContext) string {
+	return fmt.Sprintf(`Repository Context:
+- Branch: %s
+- Files changed: %d
+- Changed files:%s
+
+Git diff:
+%s`,
+		repoCtx.BranchName,
+		repoCtx.FilesChanged,
+		changedFiles(repoCtx),
+		diff)
+}
+
+// Examples returns recent commit messages of the repository as examples of its
+// style as an addition to the user prompt, or nothing if there are none
+func Examples(messages []string) string {
+	if len(messages) == 0 {
+		return ""
+	}
+	return "\n\nRecent commit messages of this repository, follow their style (prefixes, casing, ticket references, length) but describe only the changes above:" + commitList(messages)
+}
+
+// Commits returns the messages of the commits that are combined into one as an
+// addition to the user prompt, or nothing if there are none
+func Commits(messages []string) string {
+	if len(messages) == 0 {
+		return ""
+	}
+	return "\n\nThe changes are squashed from these commits, write a single commit message covering all of them:" + commitList(messages)
+}
+
+// Structured returns the addition to the user prompt asking for the commit
+// message as a JSON object, or nothing for a plain text message
+func Structured(format json.RawMessage) string {
+	if len(format) == 0 {
+		return ""
+	}
+	return `
+
+Answer with a JSON object instead of plain text:
+- "type": the kind of change, e.g. feat or fix
+- "scope": the part of the code base that changed, empty if there is none
+- "subject": the short summary of the changes without type and scope
+- "body": the detailed explanation, empty for small changes
+- "breaking": true if the changes break compatibility
+- "footers": trailers like "Refs: #123", empty if there are none`
+}
+
+// Feedback returns the user message asking the model to revise its previous commit message
+func Feedback(feedback string) string {
+	return fmt.Sprintf(`Revise the commit message based on this feedback: %s
+Output ONLY the revised commit message.`, feedback)
+}
+
+// Violations returns the user message asking the model to fix the problems of its
+// previous commit message
+func Violations(problems []string) string {
+	return fmt.Sprintf(`Your commit message does not follow the rules:
+- %s
+Fix these problems and output ONLY the corrected commit message.`, strings.Join(problems, "\n- "))
+}
+
+// Transcript renders the previous answers and the feedback of a refinement as
+// text for completion style endpoints
+func Transcript(history []llm.Message) string {
+	var sb strings.Builder
+	for _, message := range history {
+		if message.Role == llm.RoleAssistant {
+			sb.WriteString("\n\nYour previous commit message:\n")
+		} else {
+			sb.WriteString("\n\n")
+		}
+		sb.WriteString(message.Content)
+	}
+	return sb.String()
+}
+
+// changedFiles formats the changed files of the repository context as an indented list
+func changedFiles(repoCtx *git.RepoContext) string {
+	if len(repoCtx.FileChanges) == 0 {
+		return " (none)"
+	}
+	var files []string
+	for _, change := range repoCtx.FileChanges {
+		files = append(files, fmt.Sprintf("\n  - [%s] %s (%s)", change.Status, change.FilePath, change.FileType))
+	}
+	return strings.Join(files, "")
+}
+
+// SplitSystem is the system prompt used to group the staged changes into separate commits
+const SplitSystem = `You group the staged changes of a git repository into separate logical commits.
+Every commit should contain one coherent change, e.g. a feature, a fix or a refactoring, together with its tests and documentation.
+Every changed file must be part of exactly one commit. Prefer few commits, do not split changes that belong together.
+Answer ONLY with a JSON array in the order the commits should be made and no other text:
+[{"title": "short description of the change", "files": ["path/of/file", "..."]}]`
+
+// SplitUser returns the user prompt with the changed files and the diff to group into commits
+func SplitUser(diff string, repoCtx *git.RepoContext) string {
+	return fmt.Sprintf(`Changed files:%s
+
+Git diff:
+%s`, changedFiles(repoCtx), diff)
+}
+
+// SplitBudget returns the number of tokens available for the diff of the grouping request
+func SplitBudget(contextSize, responseTokens int, repoCtx *git.RepoContext) int {
+	return contextSize - responseTokens - diff.EstimateTokens(SplitSystem+SplitUser("", repoCtx))
+}
+
+// PRSystem returns the system prompt used to write the title and description of a pull request
+func PRSystem(rules string) string {
+	return fmt.Sprintf(`You write the title and description of a pull request from its commits and changes.
+Output the title on the first line, then an empty line, then the description in markdown.
+Output ONLY the title and the description with no additional text.
+
+IMPORTANT Rules:
+%s`, rules)
+}
+
+// PRUser returns the user prompt with the repository context, the commit messages
+// and the diff of a pull request
+func PRUser(diff string, commits []string, repoCtx *git.RepoContext) string {
+	return fmt.Sprintf(`Repository Context:
+- Branch: %s
+- Files changed: %d
+- Changed files:%s
+
+Commits:%s
+
+Git diff:
+%s`,
+		repoCtx.BranchName,
+		repoCtx.FilesChanged,
+		changedFiles(repoCtx),
+		commitList(commits),
+		diff)
+}
+
+// PRBudget returns the number of tokens available for the diff of a pull request
+func PRBudget(contextSize, responseTokens int, rules string, commits []string, repoCtx *git.RepoContext) int {
+	return contextSize - responseTokens - diff.EstimateTokens(PRSystem(rules)+PRUser("", commits, repoCtx))
+}
+
+// commitList formats commit messages separated by lines of dashes
+func commitList(messages []string) string {
+	var sb strings.Builder
+	for _, message := range messages {
+		sb.WriteString("\n---\n")
+		sb.WriteString(message)
+	}
+	return sb.String()
+}
+
+// ChangelogSystem is the system prompt used to sort commits into the sections of a changelog
+const ChangelogSystem = `You sort commits into the sections of a changelog in the Keep a Changelog format.
+The sections are Added, Changed, Deprecated, Removed, Fixed and Security.
+Use Skip for changes that do not matter to users of the project, e.g. refactorings, tests, CI or formatting.
+Answer ONLY with one line per commit in the form "<number>: <section>" and no other text.`
+
+// ChangelogUser returns the user prompt with the numbered commit subjects to sort
+func ChangelogUser(subjects []string) string {
+	var sb strings.Builder
+	sb.WriteString("Commits:")
+	for i, subject := range subjects {
+		fmt.Fprintf(&sb, "\n%d. %s", i+1, subject)
+	}
+	return sb.String()
+}
+
+// SummarySystem is the system prompt used to summarize the diff of a single file
+const SummarySystem = `You summarize changes of a git diff for a commit message author.
+Describe what changed and why it likely changed in at most 5 short plain text bullet points.
+Do not repeat the diff, do not add introductions or closing remarks.`
+
+// SummaryUser returns the user prompt with the diff of a single file (or part of it) to summarize
+func SummaryUser(path, diff string) string {
+	return fmt.Sprintf(`File: %s
+
+Git diff:
+%s`, path, diff)
+}
+
+// SummaryBudget returns the number of tokens available for the diff of a single summary request
+func SummaryBudget(contextSize, responseTokens int) int {
+	return contextSize - responseTokens - diff.EstimateTokens(SummarySystem+SummaryUser("", ""))
+}
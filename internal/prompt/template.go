@@ -0,0 +1,85 @@
+package prompt
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+
+	"github.com/madflow/kommit/internal/git"
+	"github.com/madflow/kommit/internal/llm"
+)
+
+// Data is available to the templates of the rules and the prompt
+type Data struct {
+	Branch       string
+	FilesChanged int
+	FileChanges  []git.FileChange
+	// Stat is the output of git diff --stat
+	Stat       string
+	Insertions int
+	Deletions  int
+	// RecentCommits are the subjects of the latest commits, newest first
+	RecentCommits []string
+	UserName      string
+	UserEmail     string
+	// Rules are the rendered rules, available to the prompt templates
+	Rules string
+	// Diff is the prepared diff, available to the prompt templates
+	Diff string
+}
+
+// NewData returns the template data of the repository context
+func NewData(repoCtx *git.RepoContext) *Data {
+	insertions, deletions := repoCtx.Stats()
+	return &Data{
+		Branch:       repoCtx.BranchName,
+		FilesChanged: repoCtx.FilesChanged,
+		FileChanges:  repoCtx.FileChanges,
+		Stat:         repoCtx.ChangeSummary,
+		Insertions:   insertions,
+		Deletions:    deletions,
+	}
+}
+
+// IsTemplate reports whether the text contains template actions
+func IsTemplate(text string) bool {
+	return strings.Contains(text, "{{")
+}
+
+// Render executes the text as Go template with the data. Text without template
+// actions is returned as it is.
+func Render(name, text string, data *Data) (string, error) {
+	if !IsTemplate(text) {
+		return text, nil
+	}
+
+	tmpl, err := template.New(name).Funcs(template.FuncMap{
+		"join":  strings.Join,
+		"lower": strings.ToLower,
+		"upper": strings.ToUpper,
+		"trim":  strings.TrimSpace,
+	}).Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("invalid %s template: %w", name, err)
+	}
+
+	var sb strings.Builder
+	if err := tmpl.Execute(&sb, data); err != nil {
+		return "", fmt.Errorf("failed to render %s template: %w", name, err)
+	}
+	return sb.String(), nil
+}
+
+// Messages returns the system and the user message of the request. The prompts
+// rendered from templates replace the built-in ones.
+func Messages(req *llm.Request) (system, user string) {
+	system = req.System
+	if system == "" {
+		system = System(req.Rules)
+	}
+	user = req.User
+	if user == "" {
+		user = User(req.Diff, req.RepoCtx)
+	}
+	return system, user + Examples(req.Examples) + Commits(req.Commits) + Structured(req.Format)
+}
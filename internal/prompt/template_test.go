@@ -0,0 +1,103 @@
+package prompt
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/madflow/kommit/internal/git"
+	"github.com/madflow/kommit/internal/llm"
+)
+
+// TestRender tests rendering the rules and prompt templates
+func TestRender(t *testing.T) {
+	data := NewData(&git.RepoContext{
+		BranchName:    "feature/PROJ-42-login",
+		FilesChanged:  2,
+		ChangeSummary: " a.go | 3 ++-\n b.go | 1 +\n 2 files changed, 3 insertions(+), 1 deletion(-)\n",
+		FileChanges: []git.FileChange{
+			{Status: "M", FilePath: "a.go", FileType: "go"},
+			{Status: "A", FilePath: "b.go", FileType: "go"},
+		},
+	})
+	data.RecentCommits = []string{"Add login form", "Fix typo"}
+	data.UserName = "Jane Doe"
+	data.Diff = "diff --git a/a.go b/a.go"
+
+	tests := []struct {
+		name     string
+		text     string
+		expected string
+		wantErr  bool
+	}{
+		{
+			name:     "plain text is kept",
+			text:     "- Use the imperative mood.",
+			expected: "- Use the imperative mood.",
+		},
+		{
+			name:     "branch and identity",
+			text:     "Written by {{.UserName}} on {{.Branch}}",
+			expected: "Written by Jane Doe on feature/PROJ-42-login",
+		},
+		{
+			name:     "stats and files",
+			text:     "{{.FilesChanged}} files, +{{.Insertions}} -{{.Deletions}}:{{range .FileChanges}} {{.Status}} {{.FilePath}}{{end}}",
+			expected: "2 files, +3 -1: M a.go A b.go",
+		},
+		{
+			name:     "recent commits",
+			text:     "Match the style of: {{join .RecentCommits \"; \"}}",
+			expected: "Match the style of: Add login form; Fix typo",
+		},
+		{
+			name:     "conditional rule",
+			text:     "{{if eq .Branch \"main\"}}- Hotfix{{else}}- Mention {{.Branch}}{{end}}",
+			expected: "- Mention feature/PROJ-42-login",
+		},
+		{
+			name:     "diff",
+			text:     "Diff:\n{{.Diff}}",
+			expected: "Diff:\ndiff --git a/a.go b/a.go",
+		},
+		{
+			name:    "unknown field",
+			text:    "{{.Author}}",
+			wantErr: true,
+		},
+		{
+			name:    "syntax error",
+			text:    "{{.Branch",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Render("rules", tt.text, data)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Render() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if result != tt.expected {
+				t.Errorf("Render() = %q, want %q", result, tt.expected)
+			}
+		})
+	}
+}
+
+// TestMessages tests replacing the built-in prompts with rendered templates
+func TestMessages(t *testing.T) {
+	repoCtx := &git.RepoContext{BranchName: "main"}
+
+	system, user := Messages(&llm.Request{Rules: "my rules", Diff: "my diff", RepoCtx: repoCtx})
+	if !strings.Contains(system, "my rules") || !strings.Contains(user, "my diff") {
+		t.Errorf("built-in prompts = %q, %q, want rules and diff", system, user)
+	}
+
+	system, user = Messages(&llm.Request{Rules: "my rules", Diff: "my diff", RepoCtx: repoCtx, System: "custom system", User: "custom user", Commits: []string{"Add a"}})
+	if system != "custom system" {
+		t.Errorf("system = %q, want %q", system, "custom system")
+	}
+	if !strings.HasPrefix(user, "custom user") || !strings.Contains(user, "Add a") {
+		t.Errorf("user = %q, want the custom prompt followed by the commits", user)
+	}
+}
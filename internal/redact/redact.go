@@ -0,0 +1,228 @@
+package redact
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// Mask replaces every detected secret
+const Mask = "[REDACTED]"
+
+// Actions when secrets are detected in the staged changes
+const (
+	// OnDetectWarn masks the secrets and prints a warning
+	OnDetectWarn = "warn"
+	// OnDetectAbort masks the secrets and aborts before anything is sent to the model
+	OnDetectAbort = "abort"
+)
+
+// Rule describes a kind of secret. If Group is greater than zero only that
+// submatch is masked, otherwise the whole match.
+type Rule struct {
+	Name    string
+	Pattern *regexp.Regexp
+	Group   int
+}
+
+// DefaultRules are the secret patterns that are always checked
+var DefaultRules = []Rule{
+	{Name: "AWS access key", Pattern: regexp.MustCompile(`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`)},
+	{Name: "GitHub token", Pattern: regexp.MustCompile(`\b(?:gh[pousr]_[A-Za-z0-9]{36,}|github_pat_[A-Za-z0-9_]{22,})`)},
+	{Name: "GitLab token", Pattern: regexp.MustCompile(`\bglpat-[A-Za-z0-9_-]{20,}`)},
+	{Name: "Slack token", Pattern: regexp.MustCompile(`\bxox[abposr]-[A-Za-z0-9-]{10,}`)},
+	{Name: "API key", Pattern: regexp.MustCompile(`\bsk-[A-Za-z0-9_-]{20,}`)},
+	{Name: "Google API key", Pattern: regexp.MustCompile(`\bAIza[0-9A-Za-z_-]{35}\b`)},
+	{Name: "JSON web token", Pattern: regexp.MustCompile(`\beyJ[A-Za-z0-9_-]{10,}\.[A-Za-z0-9_-]{10,}\.[A-Za-z0-9_-]{10,}`)},
+	{Name: "Credentials in URL", Pattern: regexp.MustCompile(`[a-zA-Z][a-zA-Z0-9+.-]*://[^/\s:@]+:([^/\s@]+)@`), Group: 1},
+	{
+		// Values must be quoted, variables and function calls assigned in code are no secrets
+		Name:    "Secret assignment",
+		Pattern: regexp.MustCompile(secretKeys + `["']?\s*(?::=|[:=])\s*["']([^\s"']{8,})["']`),
+		Group:   1,
+	},
+}
+
+// secretKeys matches the names of keys holding secrets
+const secretKeys = `(?i)(?:api[_-]?key|secret|token|passw(?:or)?d|access[_-]?key|private[_-]?key)`
+
+var (
+	privateKeyBegin = regexp.MustCompile(`-----BEGIN [A-Z0-9 ]*PRIVATE KEY( BLOCK)?-----`)
+	privateKeyEnd   = regexp.MustCompile(`-----END [A-Z0-9 ]*PRIVATE KEY( BLOCK)?-----`)
+	// configAssignment matches unquoted values of secret keys, which are only
+	// checked in configuration files
+	configAssignment = regexp.MustCompile(secretKeys + `["']?\s*[:=]\s*([^\s"',;]{8,})`)
+	envAssignment    = regexp.MustCompile(`^([+\- ]\s*(?:export\s+)?[A-Za-z_][A-Za-z0-9_]*\s*=\s*)(.+)$`)
+)
+
+// Finding is a detected secret in the diff
+type Finding struct {
+	Rule string
+	File string
+}
+
+// Redactor masks secrets in diffs
+type Redactor struct {
+	rules []Rule
+}
+
+// New creates a Redactor with the default rules and the given user defined regular expressions
+func New(patterns []string) (*Redactor, error) {
+	rules := append([]Rule{}, DefaultRules...)
+	for _, pattern := range patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid redaction pattern %q: %w", pattern, err)
+		}
+		rules = append(rules, Rule{Name: fmt.Sprintf("Pattern %q", pattern), Pattern: re})
+	}
+	return &Redactor{rules: rules}, nil
+}
+
+// Redact masks all secrets in the diff and returns the masked diff together
+// with the detected secrets (one finding per rule and file)
+func (r *Redactor) Redact(diff string) (string, []Finding) {
+	var findings []Finding
+	seen := map[Finding]bool{}
+	report := func(rule, file string) {
+		finding := Finding{Rule: rule, File: file}
+		if !seen[finding] {
+			seen[finding] = true
+			findings = append(findings, finding)
+		}
+	}
+
+	maskRules := func(line, file string) string {
+		for _, rule := range r.rules {
+			masked := maskRule(rule, line)
+			if masked != line {
+				report(rule.Name, file)
+				line = masked
+			}
+		}
+		return line
+	}
+
+	lines := strings.SplitAfter(diff, "\n")
+	file := ""
+	inPrivateKey := false
+	// The file header ends with the first hunk, content lines may look like header lines
+	inHeader := true
+	for i, line := range lines {
+		if strings.HasPrefix(line, "diff --git ") {
+			file = line[strings.LastIndex(line, " b/")+len(" b/"):]
+			file = strings.TrimRight(file, "\r\n")
+			inPrivateKey = false
+			inHeader = true
+			continue
+		}
+		if strings.HasPrefix(line, "@@") {
+			// Only the function context after the range of the hunk header can contain secrets
+			inHeader = false
+			if end := strings.Index(line[2:], "@@"); end != -1 {
+				lines[i] = line[:end+4] + maskRules(line[end+4:], file)
+			}
+			continue
+		}
+		if inHeader && isHeader(line) {
+			continue
+		}
+
+		// Mask everything between the begin and end markers of private keys
+		switch {
+		case privateKeyBegin.MatchString(line):
+			report("Private key", file)
+			inPrivateKey = !privateKeyEnd.MatchString(line)
+			continue
+		case inPrivateKey:
+			if privateKeyEnd.MatchString(line) {
+				inPrivateKey = false
+				continue
+			}
+			lines[i] = maskLine(line)
+			continue
+		}
+
+		if isEnvFile(file) {
+			if m := envAssignment.FindStringSubmatchIndex(strings.TrimRight(line, "\r\n")); m != nil && line[m[4]:m[5]] != Mask {
+				report("Environment file value", file)
+				line = line[:m[4]] + Mask + line[m[5]:]
+			}
+		}
+
+		if isConfigFile(file) {
+			if masked := maskRule(Rule{Pattern: configAssignment, Group: 1}, line); masked != line {
+				report("Secret assignment", file)
+				line = masked
+			}
+		}
+
+		lines[i] = maskRules(line, file)
+	}
+
+	return strings.Join(lines, ""), findings
+}
+
+// maskRule masks all matches of the rule in the line
+func maskRule(rule Rule, line string) string {
+	matches := rule.Pattern.FindAllStringSubmatchIndex(line, -1)
+	if matches == nil {
+		return line
+	}
+
+	var sb strings.Builder
+	last := 0
+	for _, m := range matches {
+		start, end := m[0], m[1]
+		if rule.Group > 0 && len(m) > 2*rule.Group+1 && m[2*rule.Group] >= 0 {
+			start, end = m[2*rule.Group], m[2*rule.Group+1]
+		}
+		if line[start:end] == Mask {
+			continue
+		}
+		sb.WriteString(line[last:start])
+		sb.WriteString(Mask)
+		last = end
+	}
+	sb.WriteString(line[last:])
+	return sb.String()
+}
+
+// maskLine masks the content of a diff line but keeps its +/- marker
+func maskLine(line string) string {
+	if line == "" || strings.TrimSpace(line[1:]) == "" {
+		return line
+	}
+	newline := ""
+	if strings.HasSuffix(line, "\n") {
+		newline = "\n"
+	}
+	return line[:1] + Mask + newline
+}
+
+// isHeader reports whether the line before the first hunk is part of the file header of the diff
+func isHeader(line string) bool {
+	for _, prefix := range []string{"index ", "--- ", "+++ ", "new file mode", "deleted file mode", "similarity index", "rename ", "old mode", "new mode", "Binary files"} {
+		if strings.HasPrefix(line, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// isEnvFile reports whether the file is a dotenv file (.env, .env.local, ...)
+func isEnvFile(file string) bool {
+	base := path.Base(file)
+	return base == ".env" || strings.HasPrefix(base, ".env.") || strings.HasSuffix(base, ".env")
+}
+
+// isConfigFile reports whether the file is a configuration file, in which values
+// of secret keys are masked even without quotes
+func isConfigFile(file string) bool {
+	switch path.Ext(file) {
+	case ".yaml", ".yml", ".toml", ".ini", ".cfg", ".conf", ".properties":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,129 @@
+package split
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/madflow/kommit/internal/diff"
+	"github.com/madflow/kommit/internal/git"
+	"github.com/madflow/kommit/internal/llm"
+	"github.com/madflow/kommit/internal/prompt"
+)
+
+// Group is a set of changed files that belong into one commit
+type Group struct {
+	Title   string
+	Changes []git.FileChange
+}
+
+// Paths returns all paths touched by the changes of the group
+func (g Group) Paths() []string {
+	var paths []string
+	for _, change := range g.Changes {
+		paths = append(paths, change.Paths()...)
+	}
+	return paths
+}
+
+// Context returns the repository context limited to the changes of the group
+func (g Group) Context(repoCtx *git.RepoContext) *git.RepoContext {
+	return &git.RepoContext{
+		BranchName:   repoCtx.BranchName,
+		FilesChanged: len(g.Changes),
+		FileChanges:  g.Changes,
+	}
+}
+
+// proposal is a single commit of the JSON answer of the model
+type proposal struct {
+	Title string   `json:"title"`
+	Files []string `json:"files"`
+}
+
+// Plan asks the model to group the staged changes into separate commits.
+// The diff is shortened to maxTokens.
+func Plan(ctx context.Context, provider llm.Provider, gitDiff string, repoCtx *git.RepoContext, maxTokens int) ([]Group, error) {
+	answer, err := provider.Complete(ctx, prompt.SplitSystem, prompt.SplitUser(diff.Fit(gitDiff, maxTokens).String(), repoCtx))
+	if err != nil {
+		return nil, err
+	}
+	return Parse(answer, repoCtx.FileChanges)
+}
+
+// Parse reads the groups proposed by the model. Unknown and duplicate files are
+// dropped, changes the model did not assign are collected in a final group.
+func Parse(answer string, changes []git.FileChange) ([]Group, error) {
+	start := strings.Index(answer, "[")
+	end := strings.LastIndex(answer, "]")
+	if start == -1 || end < start {
+		return nil, errors.New("the model did not answer with a list of commits")
+	}
+
+	var proposals []proposal
+	if err := json.Unmarshal([]byte(answer[start:end+1]), &proposals); err != nil {
+		return nil, fmt.Errorf("failed to parse the proposed commits: %w", err)
+	}
+
+	byPath := make(map[string]git.FileChange, len(changes))
+	for _, change := range changes {
+		byPath[change.FilePath] = change
+	}
+
+	assigned := make(map[string]bool, len(changes))
+	var groups []Group
+	for _, p := range proposals {
+		group := Group{Title: strings.TrimSpace(p.Title)}
+		for _, file := range p.Files {
+			change, ok := byPath[file]
+			if !ok || assigned[file] {
+				continue
+			}
+			assigned[file] = true
+			group.Changes = append(group.Changes, change)
+		}
+		if len(group.Changes) > 0 {
+			groups = append(groups, group)
+		}
+	}
+
+	remaining := Group{Title: "Remaining changes"}
+	for _, change := range changes {
+		if !assigned[change.FilePath] {
+			remaining.Changes = append(remaining.Changes, change)
+		}
+	}
+	if len(remaining.Changes) > 0 {
+		groups = append(groups, remaining)
+	}
+	return groups, nil
+}
+
+// Commit commits the groups one after another with their messages. The staged
+// changes are saved before and the index is restored afterwards, so that changes
+// of failed or skipped commits stay staged. It returns the number of commits created.
+func Commit(groups []Group, messages []string) (int, error) {
+	tree, err := git.WriteIndexTree()
+	if err != nil {
+		return 0, err
+	}
+
+	committed := 0
+	for i, group := range groups {
+		if err = git.StageFromTree(tree, group.Paths()); err != nil {
+			break
+		}
+		if err = git.CommitChanges(messages[i]); err != nil {
+			err = fmt.Errorf("failed to commit %q: %w", group.Title, err)
+			break
+		}
+		committed++
+	}
+
+	if restoreErr := git.RestoreIndex(tree); restoreErr != nil {
+		return committed, errors.Join(err, restoreErr)
+	}
+	return committed, err
+}
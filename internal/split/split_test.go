@@ -0,0 +1,85 @@
+package split
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/madflow/kommit/internal/git"
+)
+
+// TestParse tests reading the commits proposed by the model
+func TestParse(t *testing.T) {
+	changes := []git.FileChange{
+		{Status: "M", FilePath: "cmd/root.go", FileType: "go"},
+		{Status: "A", FilePath: "cmd/root_test.go", FileType: "go"},
+		{Status: "M", FilePath: "README.md", FileType: "md"},
+		{Status: "R100", FilePath: "docs/new.md", FileType: "md", OldPath: "docs/old.md"},
+	}
+
+	tests := []struct {
+		name     string
+		answer   string
+		expected []Group
+		hasError bool
+	}{
+		{
+			name:   "all files assigned",
+			answer: `[{"title": "Add flag", "files": ["cmd/root.go", "cmd/root_test.go"]}, {"title": "Update docs", "files": ["README.md", "docs/new.md"]}]`,
+			expected: []Group{
+				{Title: "Add flag", Changes: changes[:2]},
+				{Title: "Update docs", Changes: changes[2:]},
+			},
+		},
+		{
+			name:   "wrapped in a code block",
+			answer: "Here are the commits:\n```json\n[{\"title\": \"Everything\", \"files\": [\"cmd/root.go\", \"cmd/root_test.go\", \"README.md\", \"docs/new.md\"]}]\n```",
+			expected: []Group{
+				{Title: "Everything", Changes: changes},
+			},
+		},
+		{
+			name:   "unknown, duplicate and missing files",
+			answer: `[{"title": "Add flag", "files": ["cmd/root.go", "main.go"]}, {"title": "Again", "files": ["cmd/root.go"]}, {"title": "Docs", "files": ["README.md"]}]`,
+			expected: []Group{
+				{Title: "Add flag", Changes: changes[:1]},
+				{Title: "Docs", Changes: changes[2:3]},
+				{Title: "Remaining changes", Changes: []git.FileChange{changes[1], changes[3]}},
+			},
+		},
+		{
+			name:     "no list",
+			answer:   "I cannot do that",
+			hasError: true,
+		},
+		{
+			name:     "invalid json",
+			answer:   `[{"title": "Add flag", "files": "cmd/root.go"}]`,
+			hasError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Parse(tt.answer, changes)
+			if (err != nil) != tt.hasError {
+				t.Fatalf("Parse() error = %v, hasError %v", err, tt.hasError)
+			}
+			if !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("Parse() = %+v, want %+v", result, tt.expected)
+			}
+		})
+	}
+}
+
+// TestGroupPaths tests that renamed files contribute their old and new path
+func TestGroupPaths(t *testing.T) {
+	group := Group{Changes: []git.FileChange{
+		{Status: "M", FilePath: "a.go"},
+		{Status: "R090", FilePath: "c.go", OldPath: "b.go"},
+	}}
+
+	expected := []string{"a.go", "b.go", "c.go"}
+	if result := group.Paths(); !reflect.DeepEqual(result, expected) {
+		t.Errorf("Paths() = %v, want %v", result, expected)
+	}
+}
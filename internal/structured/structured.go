@@ -0,0 +1,111 @@
+package structured
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"text/template"
+
+	"github.com/madflow/kommit/internal/cleanup"
+)
+
+// DefaultTemplate renders the message in the conventional commits format, the
+// type is left out if the model did not return one
+const DefaultTemplate = `{{if .Type}}{{.Type}}{{if .Scope}}({{.Scope}}){{end}}{{if .Breaking}}!{{end}}: {{end}}{{.Subject}}
+{{- if .Body}}
+
+{{.Body}}{{end}}
+{{- if .Footers}}
+
+{{join .Footers "\n"}}{{end}}`
+
+// ErrInvalidAnswer is returned by Parse if the answer of the model is not a valid
+// JSON object of a commit message
+var ErrInvalidAnswer = errors.New("invalid structured answer")
+
+// Message is a commit message split into its parts
+type Message struct {
+	Type     string   `json:"type"`
+	Scope    string   `json:"scope"`
+	Subject  string   `json:"subject"`
+	Body     string   `json:"body"`
+	Breaking bool     `json:"breaking"`
+	Footers  []string `json:"footers"`
+}
+
+// Schema returns the JSON schema of a Message, used to constrain the output of
+// the model. The type is limited to the given types unless they are empty.
+func Schema(types []string) json.RawMessage {
+	typeSchema := map[string]any{"type": "string"}
+	if len(types) > 0 {
+		typeSchema["enum"] = types
+	}
+
+	schema, _ := json.Marshal(map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"type":     typeSchema,
+			"scope":    map[string]any{"type": "string"},
+			"subject":  map[string]any{"type": "string"},
+			"body":     map[string]any{"type": "string"},
+			"breaking": map[string]any{"type": "boolean"},
+			"footers":  map[string]any{"type": "array", "items": map[string]any{"type": "string"}},
+		},
+		"required":             []string{"type", "scope", "subject", "body", "breaking", "footers"},
+		"additionalProperties": false,
+	})
+	return schema
+}
+
+// Parse decodes the answer of the model. Code fences and text around the JSON
+// object are ignored.
+func Parse(answer string) (Message, error) {
+	answer = cleanup.Message(answer)
+	start := strings.Index(answer, "{")
+	end := strings.LastIndex(answer, "}")
+	if start == -1 || end < start {
+		return Message{}, fmt.Errorf("%w: no JSON object found", ErrInvalidAnswer)
+	}
+
+	var message Message
+	if err := json.Unmarshal([]byte(answer[start:end+1]), &message); err != nil {
+		return Message{}, fmt.Errorf("%w: %v", ErrInvalidAnswer, err)
+	}
+
+	message.Type = strings.TrimSpace(message.Type)
+	message.Scope = strings.TrimSpace(message.Scope)
+	message.Subject = strings.TrimSpace(message.Subject)
+	message.Body = strings.TrimSpace(message.Body)
+	footers := message.Footers[:0]
+	for _, footer := range message.Footers {
+		if footer = strings.TrimSpace(footer); footer != "" {
+			footers = append(footers, footer)
+		}
+	}
+	message.Footers = footers
+
+	if message.Subject == "" {
+		return Message{}, fmt.Errorf("%w: the subject is empty", ErrInvalidAnswer)
+	}
+	return message, nil
+}
+
+// Render renders the message with the Go template, the default template is used
+// if it is empty. The template can use the function join to concatenate lists.
+func Render(text string, message Message) (string, error) {
+	if strings.TrimSpace(text) == "" {
+		text = DefaultTemplate
+	}
+
+	tmpl, err := template.New("message").Funcs(template.FuncMap{"join": strings.Join}).Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("invalid message template: %w", err)
+	}
+
+	var sb strings.Builder
+	if err := tmpl.Execute(&sb, message); err != nil {
+		return "", fmt.Errorf("failed to render message template: %w", err)
+	}
+	return strings.TrimSpace(sb.String()), nil
+}
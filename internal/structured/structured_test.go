@@ -0,0 +1,160 @@
+package structured
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// TestParse tests decoding the answers of the model
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name     string
+		answer   string
+		expected Message
+		wantErr  bool
+	}{
+		{
+			name:   "plain JSON",
+			answer: `{"type":"feat","scope":"cli","subject":"add lint command","body":"","breaking":false,"footers":[]}`,
+			expected: Message{
+				Type:    "feat",
+				Scope:   "cli",
+				Subject: "add lint command",
+				Footers: []string{},
+			},
+		},
+		{
+			name:   "fenced JSON with preamble",
+			answer: "Here is the commit message:\n```json\n{\"type\": \"fix\", \"subject\": \" handle empty diff \", \"breaking\": true, \"footers\": [\"Refs: #12\", \" \"]}\n```",
+			expected: Message{
+				Type:     "fix",
+				Subject:  "handle empty diff",
+				Breaking: true,
+				Footers:  []string{"Refs: #12"},
+			},
+		},
+		{
+			name:    "no JSON",
+			answer:  "Add lint command",
+			wantErr: true,
+		},
+		{
+			name:    "invalid JSON",
+			answer:  `{"subject": "add lint command",}`,
+			wantErr: true,
+		},
+		{
+			name:    "empty subject",
+			answer:  `{"type":"feat","subject":""}`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Parse(tt.answer)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Parse() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr && !errors.Is(err, ErrInvalidAnswer) {
+				t.Errorf("Parse() error = %v, want ErrInvalidAnswer", err)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("Parse() = %+v, want %+v", result, tt.expected)
+			}
+		})
+	}
+}
+
+// TestRender tests rendering messages with templates
+func TestRender(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		message  Message
+		expected string
+		wantErr  bool
+	}{
+		{
+			name:     "subject only",
+			message:  Message{Type: "docs", Subject: "describe lint rules"},
+			expected: "docs: describe lint rules",
+		},
+		{
+			name: "all fields",
+			message: Message{
+				Type:     "feat",
+				Scope:    "config",
+				Subject:  "add presets",
+				Body:     "Presets bundle rules.",
+				Breaking: true,
+				Footers:  []string{"BREAKING CHANGE: rules are merged", "Refs: #7"},
+			},
+			expected: "feat(config)!: add presets\n\nPresets bundle rules.\n\nBREAKING CHANGE: rules are merged\nRefs: #7",
+		},
+		{
+			name:     "without type",
+			message:  Message{Scope: "cli", Subject: "Add lint command", Footers: []string{"Refs: #3"}},
+			expected: "Add lint command\n\nRefs: #3",
+		},
+		{
+			name:     "custom template",
+			template: "[{{.Scope}}] {{.Subject}}",
+			message:  Message{Type: "fix", Scope: "git", Subject: "Handle renames"},
+			expected: "[git] Handle renames",
+		},
+		{
+			name:     "invalid template",
+			template: "{{.Subject",
+			message:  Message{Subject: "Handle renames"},
+			wantErr:  true,
+		},
+		{
+			name:     "unknown field",
+			template: "{{.Summary}}",
+			message:  Message{Subject: "Handle renames"},
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Render(tt.template, tt.message)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Render() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if result != tt.expected {
+				t.Errorf("Render() = %q, want %q", result, tt.expected)
+			}
+		})
+	}
+}
+
+// TestSchema tests limiting the types in the schema
+func TestSchema(t *testing.T) {
+	var schema struct {
+		Properties map[string]struct {
+			Enum []string `json:"enum"`
+		} `json:"properties"`
+		Required []string `json:"required"`
+	}
+
+	if err := json.Unmarshal(Schema([]string{"feat", "fix"}), &schema); err != nil {
+		t.Fatalf("Schema() is not valid JSON: %v", err)
+	}
+	if !reflect.DeepEqual(schema.Properties["type"].Enum, []string{"feat", "fix"}) {
+		t.Errorf("type enum = %v, want [feat fix]", schema.Properties["type"].Enum)
+	}
+	if len(schema.Required) != 6 {
+		t.Errorf("required = %v, want all 6 fields", schema.Required)
+	}
+
+	if err := json.Unmarshal(Schema(nil), &schema); err != nil {
+		t.Fatalf("Schema() is not valid JSON: %v", err)
+	}
+	if schema.Properties["type"].Enum != nil {
+		t.Errorf("type enum = %v, want none", schema.Properties["type"].Enum)
+	}
+}
@@ -0,0 +1,69 @@
+package style
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/madflow/kommit/internal/git"
+)
+
+// maxExampleLength is the length of a message above which only its subject is used as example
+const maxExampleLength = 1000
+
+// Filter selects the commits whose messages are examples of the style of the repository
+type Filter struct {
+	// SkipMerges skips merge commits and messages of merges, e.g. "Merge branch 'main'"
+	SkipMerges bool
+	// SkipAuthors are parts of the names or emails of authors to skip, e.g. "[bot]"
+	SkipAuthors []string
+}
+
+// Examples returns up to n messages of the commits as examples of the style of the
+// repository, newest first. The commits are expected in the order of GetCommits,
+// oldest first. Messages generated by git, duplicates and the commits rejected by
+// the filter are skipped.
+func Examples(commits []git.Commit, n int, filter Filter) []string {
+	var examples []string
+	for i := len(commits) - 1; i >= 0 && len(examples) < n; i-- {
+		commit := commits[i]
+		if isMerge(commit) {
+			if filter.SkipMerges {
+				continue
+			}
+		} else if git.IsGeneratedSubject(commit.Subject()) {
+			continue
+		}
+		if IsBot(commit, filter.SkipAuthors) {
+			continue
+		}
+
+		message := commit.Message
+		if len(message) > maxExampleLength {
+			message = commit.Subject()
+		}
+		if message == "" || slices.Contains(examples, message) {
+			continue
+		}
+		examples = append(examples, message)
+	}
+	return examples
+}
+
+// IsBot reports whether the name or the email of the author contains one of the
+// patterns, ignoring case
+func IsBot(commit git.Commit, patterns []string) bool {
+	name := strings.ToLower(commit.AuthorName)
+	email := strings.ToLower(commit.AuthorEmail)
+	for _, pattern := range patterns {
+		pattern = strings.ToLower(strings.TrimSpace(pattern))
+		if pattern != "" && (strings.Contains(name, pattern) || strings.Contains(email, pattern)) {
+			return true
+		}
+	}
+	return false
+}
+
+// isMerge reports whether the commit is a merge or has the message of a merge
+func isMerge(commit git.Commit) bool {
+	return commit.IsMerge() || strings.HasPrefix(commit.Subject(), "Merge ")
+}
@@ -0,0 +1,85 @@
+package style
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/madflow/kommit/internal/git"
+)
+
+// TestExamples tests selecting the example messages from the history
+func TestExamples(t *testing.T) {
+	jane := func(message string) git.Commit {
+		return git.Commit{Parents: []string{"p"}, AuthorName: "Jane Doe", AuthorEmail: "jane@example.com", Message: message}
+	}
+	merge := git.Commit{Parents: []string{"p1", "p2"}, AuthorName: "Jane Doe", Message: "Merge branch 'main' into feature"}
+	bot := git.Commit{Parents: []string{"p"}, AuthorName: "dependabot[bot]", AuthorEmail: "49699333+dependabot[bot]@users.noreply.github.com", Message: "Bump golang.org/x/net"}
+	filter := Filter{SkipMerges: true, SkipAuthors: []string{"[bot]", "Renovate"}}
+
+	tests := []struct {
+		name     string
+		commits  []git.Commit
+		n        int
+		filter   Filter
+		expected []string
+	}{
+		{
+			name:     "newest first",
+			commits:  []git.Commit{jane("PROJ-1: Add login"), jane("PROJ-2: Fix logout")},
+			n:        5,
+			filter:   filter,
+			expected: []string{"PROJ-2: Fix logout", "PROJ-1: Add login"},
+		},
+		{
+			name:     "limited to n",
+			commits:  []git.Commit{jane("Add a"), jane("Add b"), jane("Add c")},
+			n:        2,
+			filter:   filter,
+			expected: []string{"Add c", "Add b"},
+		},
+		{
+			name:     "merges and bots skipped",
+			commits:  []git.Commit{jane("Add a"), merge, bot, jane("Merge pull request #4 from x/y"), {AuthorName: "Renovate Bot", Message: "Update deps"}},
+			n:        5,
+			filter:   filter,
+			expected: []string{"Add a"},
+		},
+		{
+			name:     "merges kept",
+			commits:  []git.Commit{jane("Add a"), merge},
+			n:        5,
+			filter:   Filter{},
+			expected: []string{"Merge branch 'main' into feature", "Add a"},
+		},
+		{
+			name:     "generated and duplicate messages skipped",
+			commits:  []git.Commit{jane("Add a"), jane("fixup! Add a"), jane("Revert \"Add a\""), jane("Add a")},
+			n:        5,
+			filter:   filter,
+			expected: []string{"Add a"},
+		},
+		{
+			name:     "long message reduced to the subject",
+			commits:  []git.Commit{jane("Add a\n\n" + strings.Repeat("x", maxExampleLength))},
+			n:        5,
+			filter:   filter,
+			expected: []string{"Add a"},
+		},
+		{
+			name:     "no commits",
+			n:        5,
+			filter:   filter,
+			expected: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := Examples(tt.commits, tt.n, tt.filter)
+			if !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("Examples() = %q, want %q", result, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,184 @@
+package summarize
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/madflow/kommit/internal/diff"
+	"github.com/madflow/kommit/internal/llm"
+	"github.com/madflow/kommit/internal/prompt"
+)
+
+// Summarization modes
+const (
+	// ModeNever always sends the (shortened) diff to the model
+	ModeNever = "never"
+	// ModeAuto summarizes the diff when it does not fit into the context window
+	ModeAuto = "auto"
+	// ModeAlways summarizes every diff before generating the commit message
+	ModeAlways = "always"
+)
+
+// Enabled reports whether a diff of diffTokens tokens should be summarized in the given mode
+func Enabled(mode string, diffTokens, budget int) bool {
+	switch mode {
+	case ModeAlways:
+		return true
+	case ModeAuto:
+		return diffTokens > budget
+	default:
+		return false
+	}
+}
+
+// Chunk is a part of the diff that is summarized by a single request
+type Chunk struct {
+	Path  string
+	Part  int
+	Parts int
+	Diff  string
+}
+
+// Title returns the file path of the chunk, including the part number for split files
+func (c Chunk) Title() string {
+	if c.Parts <= 1 {
+		return c.Path
+	}
+	return fmt.Sprintf("%s (part %d/%d)", c.Path, c.Part, c.Parts)
+}
+
+// Split divides the diff into one chunk per file. Files larger than maxTokens
+// are split into groups of hunks; single hunks that are still too large are shortened.
+func Split(gitDiff string, maxTokens int) []Chunk {
+	var chunks []Chunk
+	for _, file := range diff.Parse(gitDiff) {
+		if diff.EstimateTokens(file.String()) <= maxTokens || len(file.Hunks) == 0 {
+			chunks = append(chunks, Chunk{Path: file.Path, Diff: diff.Fit(file.String(), maxTokens).String()})
+			continue
+		}
+
+		var groups []string
+		var group strings.Builder
+		headerTokens := diff.EstimateTokens(file.Header)
+		for _, hunk := range file.Hunks {
+			if group.Len() > 0 && headerTokens+diff.EstimateTokens(group.String()+hunk) > maxTokens {
+				groups = append(groups, group.String())
+				group.Reset()
+			}
+			group.WriteString(hunk)
+		}
+		groups = append(groups, group.String())
+
+		for i, hunks := range groups {
+			chunks = append(chunks, Chunk{
+				Path:  file.Path,
+				Part:  i + 1,
+				Parts: len(groups),
+				Diff:  diff.Fit(file.Header+hunks, maxTokens).String(),
+			})
+		}
+	}
+	return chunks
+}
+
+// Diff summarizes every chunk of the diff with the provider, running up to
+// concurrency requests in parallel, and returns the combined summaries fitted
+// into budget tokens together with the titles of the chunks left out.
+func Diff(ctx context.Context, provider llm.Provider, gitDiff string, maxTokens, budget, concurrency int) (string, []string, error) {
+	chunks := Split(gitDiff, maxTokens)
+	summaries, err := summarizeChunks(ctx, provider, chunks, concurrency)
+	if err != nil {
+		return "", nil, err
+	}
+	result, omitted := combine(chunks, summaries, budget)
+	return result, omitted, nil
+}
+
+// summariesIntro introduces the summaries in the prompt
+const summariesIntro = "The diff is too large to show, these are summaries of the changes per file:\n"
+
+// combine joins the summaries of the chunks into roughly budget tokens. Summaries
+// that do not fit are left out as a whole and listed in a note at the end.
+func combine(chunks []Chunk, summaries []string, budget int) (string, []string) {
+	sections := make([]string, len(chunks))
+	titles := make([]string, len(chunks))
+	total := diff.EstimateTokens(summariesIntro)
+	for i, chunk := range chunks {
+		titles[i] = chunk.Title()
+		sections[i] = fmt.Sprintf("\n### %s\n%s\n", titles[i], strings.TrimSpace(summaries[i]))
+		total += diff.EstimateTokens(sections[i])
+	}
+	if total <= budget {
+		return summariesIntro + strings.Join(sections, ""), nil
+	}
+
+	// Reserve the space of the note for the case that every summary is left out
+	used := diff.EstimateTokens(summariesIntro + omittedNote(titles))
+	var sb strings.Builder
+	sb.WriteString(summariesIntro)
+	var omitted []string
+	for i, section := range sections {
+		if cost := diff.EstimateTokens(section); used+cost <= budget {
+			sb.WriteString(section)
+			used += cost
+			continue
+		}
+		omitted = append(omitted, titles[i])
+	}
+	sb.WriteString(omittedNote(omitted))
+	return sb.String(), omitted
+}
+
+// omittedNote lists the files whose summaries were left out
+func omittedNote(titles []string) string {
+	return fmt.Sprintf("\n... (summaries shortened to fit the context window)\nFiles omitted from the summaries: %s\n", strings.Join(titles, ", "))
+}
+
+// summarizeChunks requests a summary for every chunk. The first error cancels all pending requests.
+func summarizeChunks(ctx context.Context, provider llm.Provider, chunks []Chunk, concurrency int) ([]string, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	summaries := make([]string, len(chunks))
+	errs := make([]error, len(chunks))
+	semaphore := make(chan struct{}, max(concurrency, 1))
+
+	var wg sync.WaitGroup
+	for i, chunk := range chunks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			select {
+			case semaphore <- struct{}{}:
+				defer func() { <-semaphore }()
+			case <-ctx.Done():
+				errs[i] = ctx.Err()
+				return
+			}
+
+			summary, err := provider.Complete(ctx, prompt.SummarySystem, prompt.SummaryUser(chunk.Title(), chunk.Diff))
+			if err != nil {
+				errs[i] = fmt.Errorf("failed to summarize %s: %w", chunk.Title(), err)
+				cancel()
+				return
+			}
+			summaries[i] = summary
+		}()
+	}
+	wg.Wait()
+
+	// Report the error that caused the cancellation rather than the cancellation itself
+	for _, err := range errs {
+		if err != nil && !errors.Is(err, context.Canceled) {
+			return nil, err
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return summaries, nil
+}
@@ -0,0 +1,162 @@
+package summarize
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/madflow/kommit/internal/diff"
+	"github.com/madflow/kommit/internal/llm"
+)
+
+// fakeProvider returns the first line of the user prompt and tracks concurrent requests
+type fakeProvider struct {
+	mu      sync.Mutex
+	active  int
+	maximum int
+	err     error
+}
+
+func (p *fakeProvider) GenerateCommitMessage(ctx context.Context, req *llm.Request) (string, error) {
+	return "", nil
+}
+
+func (p *fakeProvider) Complete(ctx context.Context, system, user string) (string, error) {
+	p.mu.Lock()
+	p.active++
+	p.maximum = max(p.maximum, p.active)
+	p.mu.Unlock()
+
+	time.Sleep(5 * time.Millisecond)
+
+	p.mu.Lock()
+	p.active--
+	p.mu.Unlock()
+
+	if p.err != nil {
+		return "", p.err
+	}
+	return "summary of " + strings.SplitN(user, "\n", 2)[0], nil
+}
+
+func fileDiff(path string, hunks, linesPerHunk int) string {
+	var sb strings.Builder
+	sb.WriteString("diff --git a/" + path + " b/" + path + "\n--- a/" + path + "\n+++ b/" + path + "\n")
+	for range hunks {
+		sb.WriteString("@@ -1,1 +1,1 @@\n")
+		sb.WriteString(strings.Repeat("+changed line\n", linesPerHunk))
+	}
+	return sb.String()
+}
+
+// TestSplit tests splitting a diff into chunks per file and hunk group
+func TestSplit(t *testing.T) {
+	gitDiff := fileDiff("small.go", 1, 2) + fileDiff("large.go", 4, 30)
+
+	chunks := Split(gitDiff, 350)
+
+	var titles []string
+	for _, chunk := range chunks {
+		titles = append(titles, chunk.Title())
+	}
+	expected := "small.go,large.go (part 1/2),large.go (part 2/2)"
+	if strings.Join(titles, ",") != expected {
+		t.Errorf("Split() titles = %v, want %s", titles, expected)
+	}
+	for _, chunk := range chunks {
+		if !strings.HasPrefix(chunk.Diff, "diff --git a/"+chunk.Path) {
+			t.Errorf("chunk %s does not start with the file header", chunk.Title())
+		}
+	}
+}
+
+// TestDiff tests concurrent summarization of all chunks
+func TestDiff(t *testing.T) {
+	gitDiff := fileDiff("a.go", 1, 1) + fileDiff("b.go", 1, 1) + fileDiff("c.go", 1, 1) + fileDiff("d.go", 1, 1)
+
+	tests := []struct {
+		name        string
+		concurrency int
+		err         error
+	}{
+		{name: "sequential", concurrency: 1},
+		{name: "parallel", concurrency: 2},
+		{name: "error", concurrency: 2, err: errors.New("model not found")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider := &fakeProvider{err: tt.err}
+			result, omitted, err := Diff(context.Background(), provider, gitDiff, 1000, 1000, tt.concurrency)
+
+			if provider.maximum > tt.concurrency {
+				t.Errorf("%d concurrent requests, want at most %d", provider.maximum, tt.concurrency)
+			}
+			if tt.err != nil {
+				if !errors.Is(err, tt.err) {
+					t.Errorf("Diff() error = %v, want %v", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Diff() unexpected error: %v", err)
+			}
+			if len(omitted) > 0 {
+				t.Errorf("Diff() omitted %v, want none", omitted)
+			}
+			for _, path := range []string{"a.go", "b.go", "c.go", "d.go"} {
+				if !strings.Contains(result, "### "+path+"\nsummary of File: "+path) {
+					t.Errorf("Diff() result is missing summary of %s:\n%s", path, result)
+				}
+			}
+		})
+	}
+}
+
+// TestCombine tests fitting the summaries into the budget
+func TestCombine(t *testing.T) {
+	chunks := []Chunk{{Path: "a.go"}, {Path: "large.go"}, {Path: "b.go"}}
+	summaries := []string{"Adds a helper.", strings.Repeat("Rewrites the parser. ", 50), "Fixes a typo."}
+
+	result, omitted := combine(chunks, summaries, 1000)
+	if len(omitted) > 0 || !strings.Contains(result, "### large.go\n") {
+		t.Errorf("combine() = %q, %v, want all summaries", result, omitted)
+	}
+
+	result, omitted = combine(chunks, summaries, 100)
+	if !reflect.DeepEqual(omitted, []string{"large.go"}) {
+		t.Errorf("combine() omitted %v, want [large.go]", omitted)
+	}
+	if tokens := diff.EstimateTokens(result); tokens > 100 {
+		t.Errorf("combine() = %d tokens, want at most 100", tokens)
+	}
+	for _, expected := range []string{"### a.go\nAdds a helper.", "### b.go\nFixes a typo.", "Files omitted from the summaries: large.go"} {
+		if !strings.Contains(result, expected) {
+			t.Errorf("combine() = %q, missing %q", result, expected)
+		}
+	}
+}
+
+// TestEnabled tests the summarization mode selection
+func TestEnabled(t *testing.T) {
+	tests := []struct {
+		mode     string
+		tokens   int
+		expected bool
+	}{
+		{mode: ModeNever, tokens: 5000, expected: false},
+		{mode: ModeAuto, tokens: 500, expected: false},
+		{mode: ModeAuto, tokens: 5000, expected: true},
+		{mode: ModeAlways, tokens: 500, expected: true},
+	}
+
+	for _, tt := range tests {
+		if result := Enabled(tt.mode, tt.tokens, 1000); result != tt.expected {
+			t.Errorf("Enabled(%q, %d) = %v, want %v", tt.mode, tt.tokens, result, tt.expected)
+		}
+	}
+}